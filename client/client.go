@@ -0,0 +1,98 @@
+// Package client is a typed Go client for the vlrscrape REST API.
+// The endpoint methods in client_gen.go are generated from the OpenAPI document served at /openapi.json.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mrovengerdev/vlrscrape/model"
+)
+
+//go:generate go run ./gen
+
+type Client struct {
+	BaseURL    string // e.g. http://localhost:8080
+	APIKey     string // Sent as X-API-Key when set.
+	HTTPClient *http.Client
+}
+
+// A page of results returned by the Query methods.
+type Page[T any] struct {
+	Data []T            `json:"data"`
+	Meta model.PageMeta `json:"meta"`
+}
+
+// Returned for any non-200 response, carrying the server's JSON error body.
+type Error struct {
+	model.APIError
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("vlrscrape: %d %s: %s", err.Status, err.APIError.Error, err.Message)
+}
+
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Sends a GET request to path with the given query and decodes the JSON response into out.
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	if c.APIKey != "" {
+		request.Header.Set("X-API-Key", c.APIKey)
+	}
+
+	response, err := c.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		apiErr := &Error{}
+		if err := json.NewDecoder(response.Body).Decode(&apiErr.APIError); err != nil {
+			apiErr.Status = response.StatusCode
+			apiErr.APIError.Error = http.StatusText(response.StatusCode)
+		}
+		return apiErr
+	}
+
+	return json.NewDecoder(response.Body).Decode(out)
+}
+
+// Largest page the server returns.
+const maxPageSize = 500
+
+// Fetches every item of a paged endpoint by following next_cursor, which stays on the snapshot of the first page.
+func getAll[T any](ctx context.Context, c *Client, path string) ([]T, error) {
+	items := []T{}
+	query := url.Values{"limit": {strconv.Itoa(maxPageSize)}}
+	for {
+		var page Page[T]
+		if err := c.get(ctx, path, query, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page.Data...)
+		if page.Meta.NextCursor == "" {
+			return items, nil
+		}
+		query.Set("cursor", page.Meta.NextCursor)
+	}
+}
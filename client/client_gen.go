@@ -0,0 +1,106 @@
+// Code generated by go run ./gen; DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/mrovengerdev/vlrscrape/model"
+)
+
+// Rankings of a region.
+func (c *Client) GetRanking(ctx context.Context, region string) ([]model.Ranking, error) {
+	return getAll[model.Ranking](ctx, c, "/Ranking/"+url.PathEscape(region))
+}
+
+// Filters, sorts and paginates the result of GetRanking with the given query parameters.
+func (c *Client) QueryRanking(ctx context.Context, region string, query url.Values) (Page[model.Ranking], error) {
+	var result Page[model.Ranking]
+	err := c.get(ctx, "/Ranking/"+url.PathEscape(region), query, &result)
+	return result, err
+}
+
+// Newest matches snapshot.
+func (c *Client) GetMatches(ctx context.Context) ([]model.Match, error) {
+	return getAll[model.Match](ctx, c, "/matches")
+}
+
+// Filters, sorts and paginates the result of GetMatches with the given query parameters.
+func (c *Client) QueryMatches(ctx context.Context, query url.Values) (Page[model.Match], error) {
+	var result Page[model.Match]
+	err := c.get(ctx, "/matches", query, &result)
+	return result, err
+}
+
+// The matches snapshot taken at a timestamp.
+func (c *Client) GetMatchesSnapshot(ctx context.Context, timestamp string) ([]model.Match, error) {
+	return getAll[model.Match](ctx, c, "/matches/at/"+url.PathEscape(timestamp))
+}
+
+// Filters, sorts and paginates the result of GetMatchesSnapshot with the given query parameters.
+func (c *Client) QueryMatchesSnapshot(ctx context.Context, timestamp string, query url.Values) (Page[model.Match], error) {
+	var result Page[model.Match]
+	err := c.get(ctx, "/matches/at/"+url.PathEscape(timestamp), query, &result)
+	return result, err
+}
+
+// Every matches snapshot, newest first.
+func (c *Client) ListMatchesSnapshots(ctx context.Context) ([]model.Snapshot, error) {
+	var result []model.Snapshot
+	err := c.get(ctx, "/matches/snapshots", nil, &result)
+	return result, err
+}
+
+// A single match by ID with the current ranking of each team.
+func (c *Client) GetMatch(ctx context.Context, id int) (model.MatchDetail, error) {
+	var result model.MatchDetail
+	err := c.get(ctx, "/matches/"+strconv.Itoa(id), nil, &result)
+	return result, err
+}
+
+// A team with its regional rankings and upcoming matches.
+func (c *Client) GetTeam(ctx context.Context, slug string) (model.Team, error) {
+	var result model.Team
+	err := c.get(ctx, "/teams/"+url.PathEscape(slug), nil, &result)
+	return result, err
+}
+
+// Newest threads snapshot.
+func (c *Client) GetThreads(ctx context.Context) ([]model.Thread, error) {
+	return getAll[model.Thread](ctx, c, "/threads")
+}
+
+// Filters, sorts and paginates the result of GetThreads with the given query parameters.
+func (c *Client) QueryThreads(ctx context.Context, query url.Values) (Page[model.Thread], error) {
+	var result Page[model.Thread]
+	err := c.get(ctx, "/threads", query, &result)
+	return result, err
+}
+
+// The threads snapshot taken at a timestamp.
+func (c *Client) GetThreadsSnapshot(ctx context.Context, timestamp string) ([]model.Thread, error) {
+	return getAll[model.Thread](ctx, c, "/threads/at/"+url.PathEscape(timestamp))
+}
+
+// Filters, sorts and paginates the result of GetThreadsSnapshot with the given query parameters.
+func (c *Client) QueryThreadsSnapshot(ctx context.Context, timestamp string, query url.Values) (Page[model.Thread], error) {
+	var result Page[model.Thread]
+	err := c.get(ctx, "/threads/at/"+url.PathEscape(timestamp), query, &result)
+	return result, err
+}
+
+// Every threads snapshot, newest first.
+func (c *Client) ListThreadsSnapshots(ctx context.Context) ([]model.Snapshot, error) {
+	var result []model.Snapshot
+	err := c.get(ctx, "/threads/snapshots", nil, &result)
+	return result, err
+}
+
+// A single thread by ID.
+func (c *Client) GetThread(ctx context.Context, id int) (model.Thread, error) {
+	var result model.Thread
+	err := c.get(ctx, "/threads/"+strconv.Itoa(id), nil, &result)
+	return result, err
+}
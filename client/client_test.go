@@ -0,0 +1,106 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/restAPI"
+)
+
+// The checked-in spec and client must be what the generator writes for the current restAPI package.
+func TestGeneratedFilesAreCurrent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the generator")
+	}
+	dir := t.TempDir()
+	command := exec.Command("go", "run", "./gen", "-out", dir)
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("go run ./gen: %v\n%s", err, output)
+	}
+
+	for _, name := range []string{"openapi.json", "client_gen.go"} {
+		fresh, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkedIn, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(fresh, checkedIn) {
+			t.Errorf("%s is out of date, run go generate ./client", name)
+		}
+	}
+}
+
+func TestClientAgainstHandler(t *testing.T) {
+	outputDir := filepath.Join("..", "restAPI", "testdata", "output")
+	handler, _ := restAPI.NewHandler(outputDir, filepath.Join(outputDir, "ranking"), map[string]string{
+		"threads": "outputThreads",
+		"matches": "outputMatches",
+	}, nil, nil, config.Default().Server.WebSocket)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	c := New(server.URL)
+	ctx := context.Background()
+
+	threads, err := c.GetThreads(ctx)
+	if err != nil {
+		t.Fatalf("GetThreads: %v", err)
+	}
+	if len(threads) != 3 {
+		t.Errorf("GetThreads returned %d threads, want 3", len(threads))
+	}
+
+	// Cursors lead through every page of the snapshot.
+	query := url.Values{"limit": {"1"}, "sort": {"id"}}
+	var ids []int
+	for {
+		page, err := c.QueryThreads(ctx, query)
+		if err != nil {
+			t.Fatalf("QueryThreads: %v", err)
+		}
+		for _, thread := range page.Data {
+			ids = append(ids, thread.ID)
+		}
+		if page.Meta.NextCursor == "" {
+			break
+		}
+		query = url.Values{"cursor": {page.Meta.NextCursor}}
+	}
+	if len(ids) != 3 || ids[0] != 10 || ids[2] != 12 {
+		t.Errorf("QueryThreads pages held %v, want [10 11 12]", ids)
+	}
+
+	page, err := c.QueryRanking(ctx, "Europe", url.Values{"min_elo": {"1800"}})
+	if err != nil {
+		t.Fatalf("QueryRanking: %v", err)
+	}
+	if len(page.Data) != 1 || page.Data[0].TeamName != "FNATIC" {
+		t.Errorf("QueryRanking returned %+v, want only FNATIC", page.Data)
+	}
+
+	match, err := c.GetMatch(ctx, 101)
+	if err != nil {
+		t.Fatalf("GetMatch: %v", err)
+	}
+	if match.Team2Ranking == nil || match.Team2Ranking.Rank != 1 {
+		t.Errorf("GetMatch returned team 2 ranking %+v, want rank 1", match.Team2Ranking)
+	}
+
+	_, err = c.GetThread(ctx, 999)
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusNotFound {
+		t.Errorf("GetThread of an unknown ID returned %v, want a 404 Error", err)
+	}
+}
@@ -0,0 +1,167 @@
+// Generates client_gen.go and openapi.json from the OpenAPI document of the restAPI package.
+// Run through go generate in the client package. -out writes the files to another directory, which the client's
+// tests use to check the checked-in files are current.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/restAPI"
+)
+
+type method struct {
+	name     string
+	summary  string
+	args     []string // Go parameters, e.g. "id int".
+	path     string   // Go expression building the request path.
+	result   string   // Go type of the decoded response.
+	paged    bool
+	pageItem string // Element type of the Page returned by the Query method.
+}
+
+func main() {
+	outDir := flag.String("out", ".", "directory to write openapi.json and client_gen.go to")
+	flag.Parse()
+
+	cfg := config.Default()
+	_, spec := restAPI.NewHandler(os.TempDir(), os.TempDir(), map[string]string{
+		"threads": cfg.Sections.Threads.Output,
+		"matches": cfg.Sections.Matches.Output,
+	}, restAPI.NewLiveFeed(), nil, cfg.Server.WebSocket)
+
+	specJSON, err := json.MarshalIndent(spec, "", "    ")
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(*outDir, "openapi.json"), append(specJSON, '\n'), 0644); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	var paths []string
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var methods []method
+	for _, path := range paths {
+		op, ok := spec.Paths[path]["get"]
+		if !ok {
+			continue
+		}
+		response, ok := op.Responses["200"].Content["application/json"]
+		if !ok {
+			continue // Streams aren't JSON documents and need their own client.
+		}
+
+		m := method{
+			name:    strings.ToUpper(op.OperationID[:1]) + op.OperationID[1:],
+			summary: op.Summary,
+			path:    `"` + path + `"`,
+			paged:   op.Paged,
+		}
+
+		for _, param := range op.Parameters {
+			if param.In != "path" {
+				continue
+			}
+			placeholder := "{" + param.Name + "}"
+			if param.Schema.Type == "integer" {
+				m.args = append(m.args, param.Name+" int")
+				m.path = strings.Replace(m.path, placeholder, `" + strconv.Itoa(`+param.Name+`) + "`, 1)
+			} else {
+				m.args = append(m.args, param.Name+" string")
+				m.path = strings.Replace(m.path, placeholder, `" + url.PathEscape(`+param.Name+`) + "`, 1)
+			}
+		}
+		m.path = strings.TrimSuffix(m.path, ` + ""`)
+
+		schema := response.Schema
+		if op.Paged {
+			schema = schema.Properties["data"]
+			m.pageItem = goType(spec, schema.Items)
+		}
+		m.result = goType(spec, schema)
+
+		methods = append(methods, m)
+	}
+
+	var body bytes.Buffer
+	for _, m := range methods {
+		args := strings.Join(append([]string{"ctx context.Context"}, m.args...), ", ")
+
+		fmt.Fprintln(&body)
+		fmt.Fprintf(&body, "// %s\n", m.summary)
+		fmt.Fprintf(&body, "func (c *Client) %s(%s) (%s, error) {\n", m.name, args, m.result)
+		if m.paged {
+			fmt.Fprintf(&body, "return getAll[%s](ctx, c, %s)\n", m.pageItem, m.path)
+		} else {
+			fmt.Fprintf(&body, "var result %s\n", m.result)
+			fmt.Fprintf(&body, "err := c.get(ctx, %s, nil, &result)\n", m.path)
+			fmt.Fprintln(&body, "return result, err")
+		}
+		fmt.Fprintln(&body, "}")
+
+		if m.paged {
+			queryName := "Query" + strings.TrimPrefix(m.name, "Get")
+			fmt.Fprintln(&body)
+			fmt.Fprintf(&body, "// Filters, sorts and paginates the result of %s with the given query parameters.\n", m.name)
+			fmt.Fprintf(&body, "func (c *Client) %s(%s, query url.Values) (Page[%s], error) {\n", queryName, args, m.pageItem)
+			fmt.Fprintf(&body, "var result Page[%s]\n", m.pageItem)
+			fmt.Fprintf(&body, "err := c.get(ctx, %s, query, &result)\n", m.path)
+			fmt.Fprintln(&body, "return result, err")
+			fmt.Fprintln(&body, "}")
+		}
+	}
+
+	// Only import what the generated methods use.
+	var out bytes.Buffer
+	fmt.Fprintln(&out, "// Code generated by go run ./gen; DO NOT EDIT.")
+	fmt.Fprintln(&out)
+	fmt.Fprintln(&out, "package client")
+	fmt.Fprintln(&out)
+	fmt.Fprintln(&out, "import (")
+	for _, pkg := range []string{"context", "net/url", "strconv"} {
+		if pkg == "context" || strings.Contains(body.String(), filepath.Base(pkg)+".") {
+			fmt.Fprintf(&out, "%q\n", pkg)
+		}
+	}
+	fmt.Fprintln(&out)
+	for _, pkg := range []string{"github.com/mrovengerdev/vlrscrape/model"} {
+		if strings.Contains(body.String(), filepath.Base(pkg)+".") {
+			fmt.Fprintf(&out, "%q\n", pkg)
+		}
+	}
+	fmt.Fprintln(&out, ")")
+	out.Write(body.Bytes())
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatalf("Error: %v\n%s", err, out.Bytes())
+	}
+	if err := os.WriteFile(filepath.Join(*outDir, "client_gen.go"), source, 0644); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+// Maps a response schema to the Go type it was derived from.
+func goType(spec *restAPI.OpenAPI, schema *restAPI.Schema) string {
+	switch {
+	case schema.Ref != "":
+		return spec.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")].GoType
+	case schema.Type == "array":
+		return "[]" + goType(spec, schema.Items)
+	default:
+		return "any"
+	}
+}
@@ -0,0 +1,182 @@
+// Package columnar converts scraped output files to Parquet for analytics engines such as DuckDB and Spark, with one
+// typed row per thread, match or ranking and the scrape time as a timestamp column.
+package columnar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/retention"
+	"github.com/mrovengerdev/vlrscrape/scrape"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Content type of Parquet files.
+const ContentType = "application/vnd.apache.parquet"
+
+// Empty optional columns are written as null. Nullable times such as published_at are nanosecond timestamps, as
+// parquet-go can't narrow the unit of a *time.Time. Age and engagement rates are left out, as they follow from
+// published_at and scraped_at.
+type ThreadRow struct {
+	ID               int64      `parquet:"id"`
+	Title            string     `parquet:"title"`
+	ThreadURL        string     `parquet:"thread_url"`
+	FragCount        int64      `parquet:"frag_count"`
+	DatePublished    string     `parquet:"date_published,optional"`
+	DatePublishedAgo string     `parquet:"date_published_ago,optional"`
+	CommentCount     int64      `parquet:"comment_count"`
+	PublishedAt      *time.Time `parquet:"published_at,optional"`
+	ScrapedAt        time.Time  `parquet:"scraped_at,timestamp(millisecond)"`
+}
+
+// Scores are null until the match has started, and ScheduledAt when vlr.gg doesn't give a start time.
+type MatchRow struct {
+	ID             int64      `parquet:"id"`
+	MatchURL       string     `parquet:"match_url"`
+	Tournament     string     `parquet:"tournament"`
+	Team1          string     `parquet:"team1"`
+	Team2          string     `parquet:"team2"`
+	Score1         *int64     `parquet:"score1,optional"`
+	Score2         *int64     `parquet:"score2,optional"`
+	Date           string     `parquet:"date,optional"`
+	MatchTime      string     `parquet:"match_time,optional"`
+	TimeUntilMatch string     `parquet:"time_until_match,optional"`
+	ScheduledAt    *time.Time `parquet:"scheduled_at,optional"`
+	ScrapedAt      time.Time  `parquet:"scraped_at,timestamp(millisecond)"`
+}
+
+type RankingRow struct {
+	Rank      int64     `parquet:"rank"`
+	Region    string    `parquet:"region"`
+	TeamName  string    `parquet:"team_name"`
+	ELO       int64     `parquet:"elo"`
+	TeamURL   string    `parquet:"team_url,optional"`
+	ScrapedAt time.Time `parquet:"scraped_at,timestamp(millisecond)"`
+}
+
+func newThreadRow(thread scrape.Thread, scrapedAt time.Time) ThreadRow {
+	return ThreadRow{
+		ID:               int64(thread.ID),
+		Title:            thread.Title,
+		ThreadURL:        thread.ThreadURL,
+		FragCount:        int64(thread.FragCount),
+		DatePublished:    thread.DatePublished,
+		DatePublishedAgo: thread.DatePublishedAgo,
+		CommentCount:     int64(thread.CommentCount),
+		PublishedAt:      thread.PublishedAt,
+		ScrapedAt:        scrapedAt,
+	}
+}
+
+func newMatchRow(match scrape.Match, scrapedAt time.Time) MatchRow {
+	return MatchRow{
+		ID:             int64(match.ID),
+		MatchURL:       match.MatchURL,
+		Tournament:     match.Tournament,
+		Team1:          match.Team1,
+		Team2:          match.Team2,
+		Score1:         score(match.Score1),
+		Score2:         score(match.Score2),
+		Date:           match.Date,
+		MatchTime:      match.MatchTime,
+		TimeUntilMatch: match.TimeUntilMatch,
+		ScheduledAt:    match.ScheduledAt,
+		ScrapedAt:      scrapedAt,
+	}
+}
+
+func newRankingRow(ranking scrape.Ranking, scrapedAt time.Time) RankingRow {
+	return RankingRow{
+		Rank:      int64(ranking.Rank),
+		Region:    ranking.Region,
+		TeamName:  ranking.TeamName,
+		ELO:       int64(ranking.ELO),
+		TeamURL:   ranking.TeamURL,
+		ScrapedAt: scrapedAt,
+	}
+}
+
+// Returns a scraped score as a number, or nil when it's empty or not a number, such as "–" before the match.
+func score(text string) *int64 {
+	value, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// Converters from the JSON of a section's output file to Parquet. A new entity, such as player stats, needs a row
+// type and an entry here.
+var encoders = map[string]func(data []byte, scrapedAt time.Time) ([]byte, error){
+	"threads":  encoder(newThreadRow),
+	"matches":  encoder(newMatchRow),
+	"rankings": encoder(newRankingRow),
+}
+
+func encoder[T any, R any](newRow func(T, time.Time) R) func([]byte, time.Time) ([]byte, error) {
+	return func(data []byte, scrapedAt time.Time) ([]byte, error) {
+		var items []T
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		rows := make([]R, len(items))
+		for i, item := range items {
+			rows[i] = newRow(item, scrapedAt.UTC())
+		}
+
+		var file bytes.Buffer
+		writer := parquet.NewGenericWriter[R](&file, parquet.Compression(&parquet.Zstd))
+		if _, err := writer.Write(rows); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return file.Bytes(), nil
+	}
+}
+
+// Converts an output file of section, scraped at scrapedAt, to a Parquet file with one row per item.
+func Encode(section string, data []byte, scrapedAt time.Time) ([]byte, error) {
+	encode, ok := encoders[section]
+	if !ok {
+		return nil, fmt.Errorf("no Parquet schema for section %q", section)
+	}
+	return encode(data, scrapedAt)
+}
+
+// An output file recognised by Locate.
+type File struct {
+	Section   string // threads, matches or rankings.
+	Region    string // Lower-case ranking region, empty for other sections.
+	ScrapedAt time.Time
+}
+
+// Recognises an output file name: a thread or match snapshot (see retention.ParseName; sections maps each section to
+// its file prefix) or a region's rankings, <rankingDir>/output<Region>Rankings.json. Rankings have no timestamp in
+// their name, so fallback stands in for their scrape time.
+func Locate(name string, fallback time.Time, sections map[string]string, rankingDir string) (File, bool) {
+	base := path.Base(name)
+	if path.Dir(name) == rankingDir && strings.HasPrefix(base, "output") && strings.HasSuffix(base, "Rankings.json") {
+		region := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(base, "output"), "Rankings.json"))
+		return File{Section: "rankings", Region: region, ScrapedAt: fallback}, true
+	}
+	section, scrapedAt, ok := retention.ParseName(name, sections)
+	return File{Section: section, ScrapedAt: scrapedAt}, ok
+}
+
+// Returns where a Parquet copy of the file goes, partitioned by section and scrape date for engines that read Hive
+// partitions, e.g. threads/dt=2024-11-07/threads_2024-11-07_15-04-05.parquet or
+// rankings/dt=2024-11-07/rankings_europe_2024-11-07_15-04-05.parquet.
+func (file File) Path() string {
+	stem := file.Section
+	if file.Region != "" {
+		stem += "_" + file.Region
+	}
+	return file.Section + "/dt=" + file.ScrapedAt.Format("2006-01-02") + "/" + stem + "_" + file.ScrapedAt.Format(scrape.TimestampLayout) + ".parquet"
+}
@@ -0,0 +1,113 @@
+package columnar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestEncodeSchema(t *testing.T) {
+	scrapedAt := time.Date(2024, 11, 7, 16, 4, 5, 0, time.FixedZone("CET", 3600))
+	tests := []struct {
+		section string
+		data    string
+		columns []string // Column name, type and whether it's optional, in file order.
+		rows    int64
+	}{
+		{
+			section: "threads",
+			data:    `[{"id": 1, "title": "a", "frag_count": 3, "published_at": "2024-11-07T12:00:00Z"}, {"id": 2}]`,
+			columns: []string{
+				"id INT64", "title BYTE_ARRAY", "thread_url BYTE_ARRAY", "frag_count INT64",
+				"date_published BYTE_ARRAY optional", "date_published_ago BYTE_ARRAY optional", "comment_count INT64",
+				"published_at INT64 optional", "scraped_at INT64",
+			},
+			rows: 2,
+		},
+		{
+			section: "matches",
+			data:    `[{"id": 5, "team1": "FNATIC", "team2": "Sentinels", "score1": "2", "score2": "–"}]`,
+			columns: []string{
+				"id INT64", "match_url BYTE_ARRAY", "tournament BYTE_ARRAY", "team1 BYTE_ARRAY", "team2 BYTE_ARRAY",
+				"score1 INT64 optional", "score2 INT64 optional", "date BYTE_ARRAY optional", "match_time BYTE_ARRAY optional",
+				"time_until_match BYTE_ARRAY optional", "scheduled_at INT64 optional", "scraped_at INT64",
+			},
+			rows: 1,
+		},
+		{
+			section: "rankings",
+			data:    `[{"rank": 1, "region": "Europe", "team_name": "FNATIC", "elo": 1890}]`,
+			columns: []string{
+				"rank INT64", "region BYTE_ARRAY", "team_name BYTE_ARRAY", "elo INT64", "team_url BYTE_ARRAY optional", "scraped_at INT64",
+			},
+			rows: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.section, func(t *testing.T) {
+			data, err := Encode(test.section, []byte(test.data), scrapedAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if file.NumRows() != test.rows {
+				t.Errorf("%d rows, want %d", file.NumRows(), test.rows)
+			}
+
+			fields := file.Schema().Fields()
+			var columns []string
+			for _, field := range fields {
+				column := field.Name() + " " + field.Type().Kind().String()
+				if field.Optional() {
+					column += " optional"
+				}
+				columns = append(columns, column)
+			}
+			if strings.Join(columns, ", ") != strings.Join(test.columns, ", ") {
+				t.Errorf("columns are\n%s\nwant\n%s", strings.Join(columns, ", "), strings.Join(test.columns, ", "))
+			}
+
+			// scraped_at is stored in milliseconds since the epoch, which is UTC.
+			last := fields[len(fields)-1]
+			if unit := last.Type().LogicalType().Timestamp; unit == nil || unit.Unit.Millis == nil {
+				t.Errorf("scraped_at has logical type %v, want a millisecond timestamp", last.Type().LogicalType())
+			}
+		})
+	}
+}
+
+func TestEncodeRows(t *testing.T) {
+	scrapedAt := time.Date(2024, 11, 7, 16, 4, 5, 0, time.FixedZone("CET", 3600))
+	data, err := Encode("matches", []byte(`[{"id": 5, "score1": "2", "score2": "–"}, {"id": 6, "score1": " 13 "}]`), scrapedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := parquet.Read[MatchRow](bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("%d rows, want 2", len(rows))
+	}
+	if rows[0].Score1 == nil || *rows[0].Score1 != 2 || rows[0].Score2 != nil {
+		t.Errorf("scores of match 5 are %v and %v, want 2 and null", rows[0].Score1, rows[0].Score2)
+	}
+	if rows[1].Score1 == nil || *rows[1].Score1 != 13 || rows[1].Score2 != nil {
+		t.Errorf("scores of match 6 are %v and %v, want 13 and null", rows[1].Score1, rows[1].Score2)
+	}
+	if !rows[0].ScrapedAt.Equal(scrapedAt) {
+		t.Errorf("scraped_at = %v, want %v", rows[0].ScrapedAt, scrapedAt)
+	}
+}
+
+func TestEncodeUnknownSection(t *testing.T) {
+	if _, err := Encode("players", []byte(`[]`), time.Now()); err == nil {
+		t.Error("Encode accepted a section without a schema")
+	}
+}
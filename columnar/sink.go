@@ -0,0 +1,47 @@
+package columnar
+
+import (
+	"context"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/sink"
+)
+
+// Writes a Parquet copy of every thread, match and ranking file under a directory, laid out by File.Path.
+// Other files are skipped.
+type Sink struct {
+	dir        string
+	sections   map[string]string
+	rankingDir string
+	files      *sink.Local
+}
+
+func NewSink(dir string, sections config.Sections) *Sink {
+	return &Sink{
+		dir:        dir,
+		sections:   map[string]string{"threads": sections.Threads.Output, "matches": sections.Matches.Output},
+		rankingDir: sections.Rankings.Output,
+		files:      sink.NewLocal(dir),
+	}
+}
+
+func (parquet *Sink) Name() string {
+	return "parquet " + parquet.dir
+}
+
+func (parquet *Sink) Write(ctx context.Context, name string, data []byte) error {
+	file, ok := Locate(name, time.Now(), parquet.sections, parquet.rankingDir)
+	if !ok {
+		return nil
+	}
+	encoded, err := Encode(file.Section, data, file.ScrapedAt)
+	if err != nil {
+		return err
+	}
+	return parquet.files.Write(ctx, file.Path(), encoded)
+}
+
+func (parquet *Sink) Close() error {
+	return nil
+}
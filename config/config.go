@@ -0,0 +1,816 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Struct Catalog
+type Config struct {
+	BaseURL   string `json:"base_url"`
+	OutputDir string `json:"output_dir"`
+	Timezone  string `json:"timezone"` // IANA time zone the APIs display match dates and times in, e.g. Europe/Berlin.
+	// IANA time zone vlr.gg writes thread dates in, such as "Nov 6, 2024 at 3:14 PM", for lack of an offset.
+	SourceTimezone string    `json:"source_timezone"`
+	Schedule       Schedule  `json:"schedule"`
+	RateLimit      RateLimit `json:"rate_limit"`
+	Sections       Sections  `json:"sections"`
+	Live           Live      `json:"live"`
+	Storage        Storage   `json:"storage"`
+	S3             S3        `json:"s3"`
+	SQLite         SQLite    `json:"sqlite"`
+	Parquet        Parquet   `json:"parquet"`
+	Retention      Retention `json:"retention"`
+	Server         Server    `json:"server"`
+	GRPC           GRPC      `json:"grpc"`
+	Log            Log       `json:"log"`
+}
+
+// Interval of 0 runs the scrape once on startup only.
+type Schedule struct {
+	Interval Duration `json:"interval"`
+}
+
+type RateLimit struct {
+	RequestsPerSecond float64  `json:"requests_per_second"`
+	Burst             int      `json:"burst"`
+	Timeout           Duration `json:"timeout"`
+}
+
+type Sections struct {
+	Threads  Section `json:"threads"`
+	Matches  Section `json:"matches"`
+	Rankings Section `json:"rankings"`
+}
+
+// Header is appended to the section URL to pick the listing, e.g. "/?t=1w" for the weekly threads.
+type Section struct {
+	Enabled bool   `json:"enabled"`
+	Header  string `json:"header"`
+	Output  string `json:"output"`
+}
+
+// Polls the first page of matches every Interval for the live match stream.
+type Live struct {
+	Enabled  bool     `json:"enabled"`
+	Interval Duration `json:"interval"`
+}
+
+// Every scraped file is written to each sink in Sinks. The REST and gRPC APIs serve the local sink.
+type Storage struct {
+	Sinks []string `json:"sinks"`
+}
+
+// AccessKeyEnv and SecretKeyEnv name environment variables whose keys, when both are set, override the standard AWS
+// credential chain. Profile picks a profile from the shared AWS config files.
+// Endpoint overrides the AWS endpoint for S3-compatible stores such as MinIO, which usually need PathStyle as well.
+// Prune makes -upload delete objects whose local file no longer exists.
+// KeyTemplate lays out object keys using the placeholders in S3KeyPlaceholders, e.g.
+// {section}/dt={YYYY-MM-DD}/{section}_{region}_{timestamp}.json. ACL, StorageClass, ServerSideEncryption and KMSKeyID are
+// sent with every object when set; Gzip compresses objects and marks them with Content-Encoding: gzip.
+// Format is S3FormatJSON to store output files as they are, or S3FormatParquet to convert thread, match and ranking
+// files to Parquet, with a .parquet extension in place of .json in their keys.
+// -upload sends UploadConcurrency files at a time and tries each up to UploadAttempts times.
+type S3 struct {
+	Bucket               string `json:"bucket"`
+	Region               string `json:"region"`
+	Profile              string `json:"profile"`
+	Endpoint             string `json:"endpoint"`
+	PathStyle            bool   `json:"path_style"`
+	Prune                bool   `json:"prune"`
+	KeyTemplate          string `json:"key_template"`
+	ACL                  string `json:"acl"`
+	StorageClass         string `json:"storage_class"`
+	ServerSideEncryption string `json:"server_side_encryption"`
+	KMSKeyID             string `json:"kms_key_id"`
+	ContentType          string `json:"content_type"`
+	Gzip                 bool   `json:"gzip"`
+	Format               string `json:"format"`
+	UploadConcurrency    int    `json:"upload_concurrency"`
+	UploadAttempts       int    `json:"upload_attempts"`
+	Static               Static `json:"static"`
+	AccessKeyEnv         string `json:"access_key_env"`
+	SecretKeyEnv         string `json:"secret_key_env"`
+}
+
+// With Enabled, the documents the REST API serves are published as static JSON under Prefix after every scrape, with
+// CacheControl on every object. Prefix is reserved for them: anything else under it is deleted.
+type Static struct {
+	Enabled      bool   `json:"enabled"`
+	Prefix       string `json:"prefix"`
+	CacheControl string `json:"cache_control"`
+}
+
+// Placeholders allowed in s3.key_template. {name} is the output file's own name, e.g.
+// outputThreads_2024-11-07_15-04-05.json, and {region} is the lower-case ranking region, empty for other sections.
+// The date placeholders and {timestamp} give the scrape time.
+var S3KeyPlaceholders = []string{"{name}", "{section}", "{region}", "{timestamp}", "{YYYY-MM-DD}", "{YYYY}", "{MM}", "{DD}"}
+
+// Values accepted for s3.acl, s3.storage_class and s3.server_side_encryption besides empty.
+var (
+	s3ACLs                  = []string{"private", "public-read", "public-read-write", "authenticated-read", "aws-exec-read", "bucket-owner-read", "bucket-owner-full-control"}
+	s3StorageClasses        = []string{"STANDARD", "REDUCED_REDUNDANCY", "STANDARD_IA", "ONEZONE_IA", "INTELLIGENT_TIERING", "GLACIER", "DEEP_ARCHIVE", "GLACIER_IR", "OUTPOSTS", "SNOW", "EXPRESS_ONEZONE"}
+	s3ServerSideEncryptions = []string{"AES256", "aws:kms", "aws:kms:dsse"}
+)
+
+// Thins out old thread and match snapshots after every scrape, in output_dir and in the s3 bucket. Every snapshot is
+// kept for KeepAll, the newest of each hour until KeepHourly and the newest of each day until KeepDaily, or forever
+// when it's 0. The snapshots thinned out are compacted into one archive per section and day, in the Archive format.
+type Retention struct {
+	Enabled    bool     `json:"enabled"`
+	KeepAll    Duration `json:"keep_all"`
+	KeepHourly Duration `json:"keep_hourly"`
+	KeepDaily  Duration `json:"keep_daily"`
+	Archive    string   `json:"archive"`
+}
+
+// Formats of the retention archives. ArchiveNone deletes thinned out snapshots without keeping a copy.
+const (
+	ArchiveNDJSON = "ndjson.gz"
+	ArchiveTar    = "tar.gz"
+	ArchiveNone   = "none"
+)
+
+// Formats of the objects the s3 sink stores.
+const (
+	S3FormatJSON    = "json"
+	S3FormatParquet = "parquet"
+)
+
+// Folder the parquet sink writes its Parquet copies of the output files to.
+type Parquet struct {
+	Dir string `json:"dir"`
+}
+
+// Database file of the sqlite sink, created if it doesn't exist.
+type SQLite struct {
+	Path string `json:"path"`
+}
+
+// Timeouts of 0 disable the timeout. WriteTimeout doesn't apply to the live stream and WebSocket connections.
+// ShutdownTimeout bounds how long in-flight requests may take to finish once the server is stopped.
+type Server struct {
+	Enabled         bool      `json:"enabled"`
+	Addr            string    `json:"addr"`
+	ReadTimeout     Duration  `json:"read_timeout"`
+	WriteTimeout    Duration  `json:"write_timeout"`
+	IdleTimeout     Duration  `json:"idle_timeout"`
+	ShutdownTimeout Duration  `json:"shutdown_timeout"`
+	TLS             TLS       `json:"tls"`
+	Metrics         bool      `json:"metrics"` // Serves /metrics for Prometheus, outside API keys and rate limits.
+	Access          Access    `json:"access"`
+	WebSocket       WebSocket `json:"websocket"`
+}
+
+// Serves HTTPS when both files are set.
+type TLS struct {
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+}
+
+// Clients are identified by the API key they send, or by IP address when they send none.
+// Each client gets its own token bucket: KeyLimit (or the key's own Limit) for keys, IPLimit for addresses.
+type Access struct {
+	RequireAPIKey bool        `json:"require_api_key"`
+	APIKeys       []APIKey    `json:"api_keys"`
+	KeyLimit      ClientLimit `json:"key_limit"`
+	IPLimit       ClientLimit `json:"ip_limit"`
+}
+
+// Browsers may only connect to /ws from the server's own host or an origin in AllowedOrigins, such as
+// "https://example.com", or from anywhere with "*". Clients that send no Origin header are always accepted.
+type WebSocket struct {
+	AllowedOrigins []string `json:"allowed_origins"`
+	MaxTopics      int      `json:"max_topics"` // Topics a single connection may be subscribed to at once.
+}
+
+// Only the SHA-256 of a key is stored; print it with: go run . -hash-api-key <key>
+type APIKey struct {
+	Name   string       `json:"name"`
+	SHA256 string       `json:"sha256"`
+	Limit  *ClientLimit `json:"limit,omitempty"`
+}
+
+type ClientLimit struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst"`
+}
+
+// Serves the gRPC API alongside the REST API, with the API keys and rate limits of server.access.
+type GRPC struct {
+	Enabled bool   `json:"enabled"`
+	Addr    string `json:"addr"`
+}
+
+// Level is one of debug, info, warn or error, and Format either text or json.
+type Log struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+// Wraps time.Duration so it can be written as "30s" or "6h" in the config file.
+type Duration struct {
+	time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %v", err)
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// Paths of the scraped sections relative to the base URL.
+const (
+	ThreadsPath  = "/threads"
+	MatchesPath  = "/matches"
+	RankingsPath = "/rankings"
+)
+
+// Sinks recognised by the storage section.
+const (
+	SinkLocal   = "local"
+	SinkS3      = "s3"
+	SinkSQLite  = "sqlite"
+	SinkStdout  = "stdout"
+	SinkParquet = "parquet"
+)
+
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// Returns the configuration the scraper used before config files existed.
+func Default() *Config {
+	return &Config{
+		BaseURL:   "https://www.vlr.gg",
+		OutputDir: "output",
+		Timezone:  "UTC",
+		// vlr.gg renders times in US Eastern for visitors without a time zone of their own.
+		SourceTimezone: "America/New_York",
+		RateLimit: RateLimit{
+			RequestsPerSecond: 10,
+			Burst:             1,
+			Timeout:           Duration{30 * time.Second},
+		},
+		Sections: Sections{
+			Threads:  Section{Enabled: true, Header: "/?t=1w", Output: "outputThreads"},
+			Matches:  Section{Enabled: true, Header: "/?", Output: "outputMatches"},
+			Rankings: Section{Enabled: true, Output: "ranking"},
+		},
+		Live: Live{Enabled: true, Interval: Duration{15 * time.Second}},
+		// S3 needs a bucket and region first, so it is added by the config file or VLR_STORAGE_SINKS.
+		Storage: Storage{Sinks: []string{SinkLocal}},
+		S3: S3{
+			KeyTemplate:       "{name}",
+			ContentType:       "application/json",
+			Format:            S3FormatJSON,
+			UploadConcurrency: 4,
+			UploadAttempts:    3,
+			Static:            Static{Prefix: "api/", CacheControl: "public, max-age=60"},
+			AccessKeyEnv:      "AWS_VLR_ACCESS_KEY_ID",
+			SecretKeyEnv:      "AWS_VLR_SECRET_KEY",
+		},
+		SQLite:  SQLite{Path: "vlrscrape.db"},
+		Parquet: Parquet{Dir: "parquet"},
+		Retention: Retention{
+			KeepAll:    Duration{48 * time.Hour},
+			KeepHourly: Duration{14 * 24 * time.Hour},
+			Archive:    ArchiveNDJSON,
+		},
+		Server: Server{
+			Enabled:         true,
+			Addr:            ":8080",
+			ReadTimeout:     Duration{15 * time.Second},
+			WriteTimeout:    Duration{30 * time.Second},
+			IdleTimeout:     Duration{2 * time.Minute},
+			ShutdownTimeout: Duration{10 * time.Second},
+			Metrics:         true,
+			Access: Access{
+				KeyLimit: ClientLimit{RequestsPerSecond: 20, Burst: 50},
+				IPLimit:  ClientLimit{RequestsPerSecond: 5, Burst: 20},
+			},
+			WebSocket: WebSocket{MaxTopics: 100},
+		},
+		GRPC: GRPC{Addr: ":9090"},
+		Log:  Log{Level: LogLevelInfo, Format: LogFormatText},
+	}
+}
+
+// Loads the config file at path on top of the defaults, applies environment variable overrides and validates the result.
+// An empty path skips the file and only uses defaults and environment variables.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		file, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config: %w", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(file))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("parsing config %s: %w", path, err)
+		}
+	}
+
+	// A bad override is reported along with every other invalid setting instead of hiding them.
+	envErr := cfg.applyEnv()
+	if err := cfg.Validate(); err != nil || envErr != nil {
+		return nil, errors.Join(envErr, err)
+	}
+	return cfg, nil
+}
+
+// Overrides config values with any VLR_* or AWS_VLR_* environment variables that are set.
+func (c *Config) applyEnv() error {
+	var errs []error
+
+	if value, ok := os.LookupEnv("VLR_BASE_URL"); ok {
+		c.BaseURL = value
+	}
+	if value, ok := os.LookupEnv("VLR_OUTPUT_DIR"); ok {
+		c.OutputDir = value
+	}
+	if value, ok := os.LookupEnv("VLR_TIMEZONE"); ok {
+		c.Timezone = value
+	}
+	if value, ok := os.LookupEnv("VLR_SOURCE_TIMEZONE"); ok {
+		c.SourceTimezone = value
+	}
+	if value, ok := os.LookupEnv("VLR_SCHEDULE_INTERVAL"); ok {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("VLR_SCHEDULE_INTERVAL: %v", err))
+		}
+		c.Schedule.Interval = Duration{interval}
+	}
+	if value, ok := os.LookupEnv("VLR_RATE_LIMIT_RPS"); ok {
+		rps, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("VLR_RATE_LIMIT_RPS: %v", err))
+		}
+		c.RateLimit.RequestsPerSecond = rps
+	}
+	if value, ok := os.LookupEnv("VLR_RATE_LIMIT_BURST"); ok {
+		burst, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("VLR_RATE_LIMIT_BURST: %v", err))
+		}
+		c.RateLimit.Burst = burst
+	}
+	if value, ok := os.LookupEnv("VLR_RATE_LIMIT_TIMEOUT"); ok {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("VLR_RATE_LIMIT_TIMEOUT: %v", err))
+		}
+		c.RateLimit.Timeout = Duration{timeout}
+	}
+	if value, ok := os.LookupEnv("VLR_LIVE_INTERVAL"); ok {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("VLR_LIVE_INTERVAL: %v", err))
+		}
+		c.Live.Interval = Duration{interval}
+	}
+	if value, ok := os.LookupEnv("VLR_STORAGE_SINKS"); ok {
+		c.Storage.Sinks = nil
+		for _, sink := range strings.Split(value, ",") {
+			if sink = strings.TrimSpace(sink); sink != "" {
+				c.Storage.Sinks = append(c.Storage.Sinks, sink)
+			}
+		}
+	}
+	if value, ok := os.LookupEnv("VLR_SERVER_ADDR"); ok {
+		c.Server.Addr = value
+	}
+	if value, ok := os.LookupEnv("VLR_TLS_CERT_FILE"); ok {
+		c.Server.TLS.CertFile = value
+	}
+	if value, ok := os.LookupEnv("VLR_TLS_KEY_FILE"); ok {
+		c.Server.TLS.KeyFile = value
+	}
+	if value, ok := os.LookupEnv("VLR_REQUIRE_API_KEY"); ok {
+		required, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("VLR_REQUIRE_API_KEY: %v", err))
+		}
+		c.Server.Access.RequireAPIKey = required
+	}
+	if value, ok := os.LookupEnv("VLR_GRPC_ADDR"); ok {
+		c.GRPC.Addr = value
+	}
+	if value, ok := os.LookupEnv("VLR_LOG_LEVEL"); ok {
+		c.Log.Level = strings.ToLower(value)
+	}
+	if value, ok := os.LookupEnv("VLR_LOG_FORMAT"); ok {
+		c.Log.Format = strings.ToLower(value)
+	}
+	if value, ok := os.LookupEnv("AWS_VLR_S3_BUCKET"); ok {
+		c.S3.Bucket = value
+	}
+	if value, ok := os.LookupEnv("AWS_VLR_S3_REGION"); ok {
+		c.S3.Region = value
+	}
+	if value, ok := os.LookupEnv("AWS_VLR_S3_ENDPOINT"); ok {
+		c.S3.Endpoint = value
+	}
+	if value, ok := os.LookupEnv("AWS_VLR_S3_KEY_TEMPLATE"); ok {
+		c.S3.KeyTemplate = value
+	}
+
+	return errors.Join(errs...)
+}
+
+// Reports every invalid setting at once so a bad config fails at startup rather than partway through a scrape.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if parsed, err := url.Parse(c.BaseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		errs = append(errs, fmt.Errorf("base_url %q must be an absolute http(s) URL", c.BaseURL))
+	}
+	if strings.HasSuffix(c.BaseURL, "/") {
+		errs = append(errs, fmt.Errorf("base_url %q must not end with a slash", c.BaseURL))
+	}
+	if c.OutputDir == "" {
+		errs = append(errs, errors.New("output_dir must not be empty"))
+	}
+	if _, err := time.LoadLocation(c.Timezone); c.Timezone == "" || err != nil {
+		errs = append(errs, fmt.Errorf("timezone %q must be an IANA time zone such as UTC or Europe/Berlin", c.Timezone))
+	}
+	if _, err := time.LoadLocation(c.SourceTimezone); c.SourceTimezone == "" || err != nil {
+		errs = append(errs, fmt.Errorf("source_timezone %q must be an IANA time zone such as America/New_York", c.SourceTimezone))
+	}
+	if c.Schedule.Interval.Duration < 0 {
+		errs = append(errs, errors.New("schedule.interval must not be negative"))
+	}
+
+	if c.RateLimit.RequestsPerSecond <= 0 {
+		errs = append(errs, errors.New("rate_limit.requests_per_second must be greater than 0"))
+	}
+	if c.RateLimit.Burst < 1 {
+		errs = append(errs, errors.New("rate_limit.burst must be at least 1"))
+	}
+	if c.RateLimit.Timeout.Duration <= 0 {
+		errs = append(errs, errors.New("rate_limit.timeout must be greater than 0"))
+	}
+
+	for _, named := range []struct {
+		name    string
+		section Section
+	}{
+		{"threads", c.Sections.Threads},
+		{"matches", c.Sections.Matches},
+		{"rankings", c.Sections.Rankings},
+	} {
+		name, section := named.name, named.section
+		if !section.Enabled {
+			continue
+		}
+		if name != "rankings" && !strings.HasPrefix(section.Header, "/?") {
+			errs = append(errs, fmt.Errorf("sections.%s.header %q must start with \"/?\" so pages can be appended", name, section.Header))
+		}
+		if section.Output == "" || strings.ContainsAny(section.Output, `/\`) {
+			errs = append(errs, fmt.Errorf("sections.%s.output %q must be a plain file name", name, section.Output))
+		}
+	}
+
+	if c.Live.Enabled {
+		if c.Live.Interval.Duration < time.Second {
+			errs = append(errs, errors.New("live.interval must be at least 1s"))
+		}
+		if !c.Sections.Matches.Enabled {
+			errs = append(errs, errors.New("live requires the matches section to be enabled"))
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, sink := range c.Storage.Sinks {
+		if seen[sink] {
+			errs = append(errs, fmt.Errorf("storage.sinks: %q is listed twice", sink))
+			continue
+		}
+		seen[sink] = true
+
+		switch sink {
+		case SinkLocal:
+		case SinkS3:
+			if c.S3.Bucket == "" {
+				errs = append(errs, errors.New("s3.bucket is required when the s3 sink is enabled"))
+			}
+			if c.S3.Region == "" {
+				errs = append(errs, errors.New("s3.region is required when the s3 sink is enabled"))
+			}
+			if (c.S3.AccessKeyEnv == "") != (c.S3.SecretKeyEnv == "") {
+				errs = append(errs, errors.New("s3.access_key_env and s3.secret_key_env must be set together, or both left empty to always use the AWS credential chain"))
+			}
+			if c.S3.Endpoint != "" {
+				if parsed, err := url.Parse(c.S3.Endpoint); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+					errs = append(errs, fmt.Errorf("s3.endpoint %q must be an absolute http(s) URL", c.S3.Endpoint))
+				}
+			}
+			errs = append(errs, c.S3.validate()...)
+			errs = append(errs, c.validateKeyTemplate()...)
+		case SinkSQLite:
+			if c.SQLite.Path == "" {
+				errs = append(errs, errors.New("sqlite.path is required when the sqlite sink is enabled"))
+			}
+		case SinkStdout:
+		case SinkParquet:
+			if c.Parquet.Dir == "" || filepath.Clean(c.Parquet.Dir) == filepath.Clean(c.OutputDir) {
+				errs = append(errs, fmt.Errorf("parquet.dir %q must be set and differ from output_dir", c.Parquet.Dir))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("storage.sinks: unknown sink %q", sink))
+		}
+	}
+
+	if c.Retention.Enabled {
+		retention := c.Retention
+		if retention.KeepAll.Duration < 0 || retention.KeepHourly.Duration < retention.KeepAll.Duration {
+			errs = append(errs, errors.New("retention.keep_all must not be negative, and retention.keep_hourly must be at least as long"))
+		}
+		if retention.KeepDaily.Duration != 0 && retention.KeepDaily.Duration < retention.KeepHourly.Duration {
+			errs = append(errs, errors.New("retention.keep_daily must be 0 to keep daily snapshots forever, or at least retention.keep_hourly"))
+		}
+		if retention.Archive != ArchiveNDJSON && retention.Archive != ArchiveTar && retention.Archive != ArchiveNone {
+			errs = append(errs, fmt.Errorf("retention.archive %q must be %s, %s or %s", retention.Archive, ArchiveNDJSON, ArchiveTar, ArchiveNone))
+		}
+		if c.Sections.Rankings.Output == "archive" {
+			errs = append(errs, errors.New("sections.rankings.output must not be archive, which retention uses for its archives"))
+		}
+		// Snapshots in the bucket are recognised by the scrape time in their key.
+		if template := c.S3.KeyTemplate; c.HasSink(SinkS3) && !strings.Contains(template, "{name}") &&
+			(!strings.Contains(template, "{section}") || !strings.Contains(template, "{timestamp}")) {
+			errs = append(errs, fmt.Errorf("retention needs s3.key_template %q to contain {name}, or {section} and {timestamp}", template))
+		}
+		if c.HasSink(SinkS3) && c.S3.Format != S3FormatJSON {
+			errs = append(errs, fmt.Errorf("retention needs s3.format %q, since it archives the JSON snapshots in the bucket", S3FormatJSON))
+		}
+	}
+
+	if c.S3.Static.Enabled && (!c.HasSink(SinkLocal) || !c.HasSink(SinkS3)) {
+		errs = append(errs, errors.New("s3.static needs both the local and s3 sinks, since it's rendered from output_dir"))
+	}
+
+	if c.Server.Enabled {
+		if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("server.addr %q: %v", c.Server.Addr, err))
+		}
+		if c.Server.ReadTimeout.Duration < 0 || c.Server.WriteTimeout.Duration < 0 || c.Server.IdleTimeout.Duration < 0 {
+			errs = append(errs, errors.New("server.read_timeout, server.write_timeout and server.idle_timeout must not be negative"))
+		}
+		if c.Server.ShutdownTimeout.Duration <= 0 {
+			errs = append(errs, errors.New("server.shutdown_timeout must be greater than 0"))
+		}
+		if (c.Server.TLS.CertFile == "") != (c.Server.TLS.KeyFile == "") {
+			errs = append(errs, errors.New("server.tls.cert_file and server.tls.key_file must be set together"))
+		}
+		for _, file := range []string{c.Server.TLS.CertFile, c.Server.TLS.KeyFile} {
+			if file == "" {
+				continue
+			}
+			if _, err := os.Stat(file); err != nil {
+				errs = append(errs, fmt.Errorf("server.tls: %v", err))
+			}
+		}
+		errs = append(errs, c.Server.Access.validate()...)
+		errs = append(errs, c.Server.WebSocket.validate()...)
+	}
+	if c.GRPC.Enabled {
+		if _, _, err := net.SplitHostPort(c.GRPC.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("grpc.addr %q: %v", c.GRPC.Addr, err))
+		}
+		if c.Server.Enabled && c.GRPC.Addr == c.Server.Addr {
+			errs = append(errs, fmt.Errorf("grpc.addr %q is already used by server.addr", c.GRPC.Addr))
+		}
+		// Checked above when the REST API is enabled too.
+		if !c.Server.Enabled {
+			errs = append(errs, c.Server.Access.validate()...)
+		}
+	}
+
+	switch c.Log.Level {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+	default:
+		errs = append(errs, fmt.Errorf("log.level %q must be debug, info, warn or error", c.Log.Level))
+	}
+	if c.Log.Format != LogFormatText && c.Log.Format != LogFormatJSON {
+		errs = append(errs, fmt.Errorf("log.format %q must be text or json", c.Log.Format))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (s3 S3) validate() []error {
+	var errs []error
+
+	template := s3.KeyTemplate
+	for _, placeholder := range S3KeyPlaceholders {
+		template = strings.ReplaceAll(template, placeholder, "")
+	}
+	switch {
+	case s3.KeyTemplate == "":
+		errs = append(errs, errors.New("s3.key_template must not be empty"))
+	case strings.ContainsAny(template, "{}"):
+		errs = append(errs, fmt.Errorf("s3.key_template %q may only use the placeholders %s", s3.KeyTemplate, strings.Join(S3KeyPlaceholders, ", ")))
+	case strings.HasPrefix(s3.KeyTemplate, "/") || strings.HasPrefix(s3.KeyTemplate, "manifests/"):
+		errs = append(errs, fmt.Errorf("s3.key_template %q must not start with / or the reserved manifests/ prefix", s3.KeyTemplate))
+	}
+
+	if s3.ACL != "" && !slices.Contains(s3ACLs, s3.ACL) {
+		errs = append(errs, fmt.Errorf("s3.acl %q must be empty or one of %s", s3.ACL, strings.Join(s3ACLs, ", ")))
+	}
+	if s3.StorageClass != "" && !slices.Contains(s3StorageClasses, s3.StorageClass) {
+		errs = append(errs, fmt.Errorf("s3.storage_class %q must be empty or one of %s", s3.StorageClass, strings.Join(s3StorageClasses, ", ")))
+	}
+	if s3.ServerSideEncryption != "" && !slices.Contains(s3ServerSideEncryptions, s3.ServerSideEncryption) {
+		errs = append(errs, fmt.Errorf("s3.server_side_encryption %q must be empty or one of %s", s3.ServerSideEncryption, strings.Join(s3ServerSideEncryptions, ", ")))
+	}
+	if s3.KMSKeyID != "" && !strings.HasPrefix(s3.ServerSideEncryption, "aws:kms") {
+		errs = append(errs, errors.New("s3.kms_key_id needs s3.server_side_encryption set to aws:kms or aws:kms:dsse"))
+	}
+	if s3.ContentType == "" {
+		errs = append(errs, errors.New("s3.content_type must not be empty"))
+	}
+	switch s3.Format {
+	case S3FormatJSON:
+	case S3FormatParquet:
+		if s3.Gzip {
+			errs = append(errs, errors.New("s3.gzip must be off with s3.format parquet, which is compressed already"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("s3.format %q must be %s or %s", s3.Format, S3FormatJSON, S3FormatParquet))
+	}
+	if s3.UploadConcurrency < 1 {
+		errs = append(errs, errors.New("s3.upload_concurrency must be at least 1"))
+	}
+	if s3.UploadAttempts < 1 {
+		errs = append(errs, errors.New("s3.upload_attempts must be at least 1"))
+	}
+
+	if s3.Static.Enabled {
+		switch {
+		case s3.Static.Prefix == "" || !strings.HasSuffix(s3.Static.Prefix, "/") || strings.HasPrefix(s3.Static.Prefix, "/"):
+			errs = append(errs, fmt.Errorf("s3.static.prefix %q must be a folder such as api/, since everything else under it is deleted", s3.Static.Prefix))
+		case strings.HasPrefix(s3.Static.Prefix, "manifests/"):
+			errs = append(errs, fmt.Errorf("s3.static.prefix %q is inside the reserved manifests/ prefix", s3.Static.Prefix))
+		case strings.HasPrefix(s3.KeyTemplate, s3.Static.Prefix):
+			errs = append(errs, fmt.Errorf("s3.key_template %q would put output files under s3.static.prefix %q", s3.KeyTemplate, s3.Static.Prefix))
+		}
+	}
+
+	return errs
+}
+
+// Checks that s3.key_template gives every file scraped in one run a key of its own: each section needs {section}
+// when several are enabled, and each ranking region needs {region}. {name} is unique by itself.
+func (c *Config) validateKeyTemplate() []error {
+	template := c.S3.KeyTemplate
+	if strings.Contains(template, "{name}") {
+		return nil
+	}
+
+	var errs []error
+	enabled := 0
+	for _, section := range []Section{c.Sections.Threads, c.Sections.Matches, c.Sections.Rankings} {
+		if section.Enabled {
+			enabled++
+		}
+	}
+	if enabled > 1 && !strings.Contains(template, "{section}") {
+		errs = append(errs, fmt.Errorf("s3.key_template %q must contain {name} or {section}, or the sections overwrite each other", template))
+	}
+	if c.Sections.Rankings.Enabled && !strings.Contains(template, "{region}") {
+		errs = append(errs, fmt.Errorf("s3.key_template %q must contain {name} or {region}, or every region's rankings get the same key", template))
+	}
+	return errs
+}
+
+func (ws WebSocket) validate() []error {
+	var errs []error
+
+	if ws.MaxTopics < 1 {
+		errs = append(errs, errors.New("server.websocket.max_topics must be at least 1"))
+	}
+	for i, origin := range ws.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || strings.TrimSuffix(parsed.Path, "/") != "" {
+			errs = append(errs, fmt.Errorf("server.websocket.allowed_origins[%d] %q must be * or a scheme and host such as https://example.com", i, origin))
+		}
+	}
+	return errs
+}
+
+func (access Access) validate() []error {
+	var errs []error
+
+	if access.RequireAPIKey && len(access.APIKeys) == 0 {
+		errs = append(errs, errors.New("server.access.require_api_key needs at least one key in server.access.api_keys"))
+	}
+	errs = append(errs, access.KeyLimit.validate("server.access.key_limit")...)
+	errs = append(errs, access.IPLimit.validate("server.access.ip_limit")...)
+
+	names := map[string]bool{}
+	for i, key := range access.APIKeys {
+		if key.Name == "" {
+			errs = append(errs, fmt.Errorf("server.access.api_keys[%d].name must not be empty", i))
+		} else if names[key.Name] {
+			errs = append(errs, fmt.Errorf("server.access.api_keys[%d].name %q is used by another key", i, key.Name))
+		}
+		names[key.Name] = true
+
+		if decoded, err := hex.DecodeString(key.SHA256); err != nil || len(decoded) != sha256.Size {
+			errs = append(errs, fmt.Errorf("server.access.api_keys[%d].sha256 must be a hex SHA-256 hash, not the key itself", i))
+		}
+		if key.Limit != nil {
+			errs = append(errs, key.Limit.validate(fmt.Sprintf("server.access.api_keys[%d].limit", i))...)
+		}
+	}
+
+	return errs
+}
+
+func (limit ClientLimit) validate(name string) []error {
+	var errs []error
+	if limit.RequestsPerSecond <= 0 {
+		errs = append(errs, fmt.Errorf("%s.requests_per_second must be greater than 0", name))
+	}
+	if limit.Burst < 1 {
+		errs = append(errs, fmt.Errorf("%s.burst must be at least 1", name))
+	}
+	return errs
+}
+
+// Returns the hex SHA-256 of an API key, as stored in server.access.api_keys.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Reports whether the named storage sink is enabled.
+func (c *Config) HasSink(sink string) bool {
+	for _, enabled := range c.Storage.Sinks {
+		if enabled == sink {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the time zone named by Timezone, or UTC when it's invalid, which Load reports.
+func (c *Config) Location() *time.Location {
+	return loadLocation(c.Timezone)
+}
+
+// Returns the time zone named by SourceTimezone, or UTC when it's invalid, which Load reports.
+func (c *Config) SourceLocation() *time.Location {
+	return loadLocation(c.SourceTimezone)
+}
+
+func loadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// Returns the full URL of a section path, such as https://www.vlr.gg/threads.
+func (c *Config) SectionURL(path string) string {
+	return c.BaseURL + path
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Unsets every VLR_* and AWS_VLR_* variable for the duration of the test, so overrides from the environment the
+// tests run in don't leak into them.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, "VLR_") && !strings.HasPrefix(name, "AWS_VLR_") {
+			continue
+		}
+		os.Unsetenv(name)
+		t.Cleanup(func() { os.Setenv(name, value) })
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("the defaults don't validate: %v", err)
+	}
+	if !slices.Equal(cfg.Storage.Sinks, []string{SinkLocal}) {
+		t.Errorf("storage.sinks = %v, want only local", cfg.Storage.Sinks)
+	}
+	if cfg.S3.ACL != "" {
+		t.Errorf("s3.acl = %q, want the bucket's own", cfg.S3.ACL)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		err   string // Part of the expected error, empty when Load should succeed.
+		check func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "partial file keeps the other defaults",
+			file: `{"output_dir": "data", "schedule": {"interval": "5m"}, "server": {"addr": ":9000"}}`,
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.OutputDir != "data" || cfg.Schedule.Interval.Duration != 5*time.Minute || cfg.Server.Addr != ":9000" {
+					t.Errorf("got output_dir %q, schedule.interval %v and server.addr %q", cfg.OutputDir, cfg.Schedule.Interval, cfg.Server.Addr)
+				}
+				if cfg.RateLimit != Default().RateLimit || cfg.Server.ShutdownTimeout != Default().Server.ShutdownTimeout {
+					t.Error("settings missing from the file lost their defaults")
+				}
+			},
+		},
+		{
+			name: "s3 sink with a bucket",
+			file: `{"storage": {"sinks": ["local", "s3"]}, "s3": {"bucket": "vlr", "region": "eu-west-1"}}`,
+			check: func(t *testing.T, cfg *Config) {
+				if !cfg.HasSink(SinkS3) || cfg.S3.KeyTemplate != "{name}" {
+					t.Errorf("got sinks %v and key template %q", cfg.Storage.Sinks, cfg.S3.KeyTemplate)
+				}
+			},
+		},
+		{name: "unknown field", file: `{"output_folder": "data"}`, err: `unknown field "output_folder"`},
+		{name: "duration as a number", file: `{"schedule": {"interval": 300}}`, err: `duration must be a string`},
+		{name: "invalid duration", file: `{"live": {"interval": "often"}}`, err: `invalid duration`},
+		{name: "invalid settings", file: `{"base_url": "vlr.gg", "storage": {"sinks": ["s3"]}}`, err: "s3.bucket is required"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearEnv(t)
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(test.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(path)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("Load returned %v, want an error containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.check(t, cfg)
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil || !strings.Contains(err.Error(), "reading config") {
+		t.Errorf("Load of a missing file returned %v", err)
+	}
+}
+
+func TestEnvOverrides(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		err   []string // Parts of the expected error, all of which must be reported.
+		check func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "overrides",
+			env: map[string]string{
+				"VLR_OUTPUT_DIR":        "data",
+				"VLR_SCHEDULE_INTERVAL": "10m",
+				"VLR_RATE_LIMIT_BURST":  "3",
+				"VLR_STORAGE_SINKS":     " local , s3 ,",
+				"AWS_VLR_S3_BUCKET":     "vlr",
+				"AWS_VLR_S3_REGION":     "us-east-1",
+				"VLR_LOG_LEVEL":         "DEBUG",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.OutputDir != "data" || cfg.Schedule.Interval.Duration != 10*time.Minute || cfg.RateLimit.Burst != 3 {
+					t.Errorf("got output_dir %q, schedule.interval %v and rate_limit.burst %d", cfg.OutputDir, cfg.Schedule.Interval, cfg.RateLimit.Burst)
+				}
+				if !slices.Equal(cfg.Storage.Sinks, []string{SinkLocal, SinkS3}) || cfg.S3.Bucket != "vlr" {
+					t.Errorf("got sinks %v and bucket %q", cfg.Storage.Sinks, cfg.S3.Bucket)
+				}
+				if cfg.Log.Level != LogLevelDebug {
+					t.Errorf("log.level = %q, want it lower-cased", cfg.Log.Level)
+				}
+			},
+		},
+		{
+			name: "unparsable values are reported with invalid settings",
+			env: map[string]string{
+				"VLR_RATE_LIMIT_RPS":  "fast",
+				"VLR_REQUIRE_API_KEY": "maybe",
+				"VLR_BASE_URL":        "https://www.vlr.gg/",
+			},
+			err: []string{"VLR_RATE_LIMIT_RPS", "VLR_REQUIRE_API_KEY", "base_url", "rate_limit.requests_per_second"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+			cfg, err := Load("")
+			if len(test.err) > 0 {
+				if err == nil {
+					t.Fatal("Load succeeded")
+				}
+				for _, part := range test.err {
+					if !strings.Contains(err.Error(), part) {
+						t.Errorf("error %q doesn't mention %s", err, part)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.check(t, cfg)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(cfg *Config)
+		err    string // Part of the expected error, empty when the config is valid.
+	}{
+		{name: "defaults", change: func(cfg *Config) {}},
+		{name: "relative base URL", change: func(cfg *Config) { cfg.BaseURL = "www.vlr.gg" }, err: "base_url"},
+		{name: "unknown time zone", change: func(cfg *Config) { cfg.Timezone = "Mars/Olympus" }, err: "timezone"},
+		{name: "header without query", change: func(cfg *Config) { cfg.Sections.Threads.Header = "threads" }, err: "sections.threads.header"},
+		{name: "output with a path", change: func(cfg *Config) { cfg.Sections.Matches.Output = "a/b" }, err: "sections.matches.output"},
+		{name: "disabled sections aren't checked", change: func(cfg *Config) {
+			cfg.Sections.Threads = Section{Header: "threads"}
+		}},
+		{name: "live without matches", change: func(cfg *Config) { cfg.Sections.Matches.Enabled = false }, err: "live requires the matches section"},
+		{name: "unknown sink", change: func(cfg *Config) { cfg.Storage.Sinks = []string{"ftp"} }, err: `unknown sink "ftp"`},
+		{name: "sink listed twice", change: func(cfg *Config) { cfg.Storage.Sinks = []string{SinkLocal, SinkLocal} }, err: "listed twice"},
+		{name: "s3 without bucket", change: func(cfg *Config) { cfg.Storage.Sinks = []string{SinkS3} }, err: "s3.bucket is required"},
+		{name: "key template without section", change: func(cfg *Config) {
+			cfg.Storage.Sinks = []string{SinkS3}
+			cfg.S3.Bucket = "vlr"
+			cfg.S3.Region = "us-east-1"
+			cfg.S3.KeyTemplate = "dt={YYYY-MM-DD}/{region}_{timestamp}.json"
+		}, err: "must contain {name} or {section}"},
+		{name: "key template without region", change: func(cfg *Config) {
+			cfg.Storage.Sinks = []string{SinkS3}
+			cfg.S3.Bucket = "vlr"
+			cfg.S3.Region = "us-east-1"
+			cfg.S3.KeyTemplate = "{section}/dt={YYYY-MM-DD}/{section}_{timestamp}.json"
+		}, err: "must contain {name} or {region}"},
+		{name: "key template without region for threads alone", change: func(cfg *Config) {
+			cfg.Storage.Sinks = []string{SinkS3}
+			cfg.S3.Bucket = "vlr"
+			cfg.S3.Region = "us-east-1"
+			cfg.S3.KeyTemplate = "dt={YYYY-MM-DD}/{timestamp}.json"
+			cfg.Sections.Matches.Enabled = false
+			cfg.Sections.Rankings.Enabled = false
+			cfg.Live.Enabled = false
+		}},
+		{name: "static without s3", change: func(cfg *Config) { cfg.S3.Static.Enabled = true }, err: "s3.static needs"},
+		{name: "gRPC on the REST address", change: func(cfg *Config) { cfg.GRPC = GRPC{Enabled: true, Addr: cfg.Server.Addr} }, err: "grpc.addr"},
+		{name: "required key without keys", change: func(cfg *Config) { cfg.Server.Access.RequireAPIKey = true }, err: "require_api_key"},
+		{name: "plain key instead of hash", change: func(cfg *Config) {
+			cfg.Server.Access.APIKeys = []APIKey{{Name: "app", SHA256: "secret"}}
+		}, err: "api_keys[0].sha256"},
+		{name: "origin with a path", change: func(cfg *Config) {
+			cfg.Server.WebSocket.AllowedOrigins = []string{"https://example.com/app"}
+		}, err: "allowed_origins[0]"},
+		{name: "access checked for gRPC alone", change: func(cfg *Config) {
+			cfg.Server.Enabled = false
+			cfg.GRPC.Enabled = true
+			cfg.Server.Access.IPLimit.Burst = 0
+		}, err: "server.access.ip_limit.burst"},
+		{name: "unknown log level", change: func(cfg *Config) { cfg.Log.Level = "trace" }, err: "log.level"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := Default()
+			test.change(cfg)
+			err := cfg.Validate()
+			if test.err == "" {
+				if err != nil {
+					t.Errorf("Validate returned %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Validate returned %v, want an error containing %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryError(t *testing.T) {
+	cfg := Default()
+	cfg.OutputDir = ""
+	cfg.RateLimit.Burst = 0
+	cfg.Log.Format = "xml"
+	err := cfg.Validate()
+	for _, part := range []string{"output_dir", "rate_limit.burst", "log.format"} {
+		if err == nil || !strings.Contains(err.Error(), part) {
+			t.Errorf("Validate returned %v, which doesn't mention %s", err, part)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package logging builds the log/slog logger handed to every other package, with credentials redacted
+// from every record before it is written.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+)
+
+// Written in place of a redacted value.
+const Redacted = "[REDACTED]"
+
+// Secrets shorter than this aren't registered, since replacing them would mangle unrelated text.
+const minSecretLength = 8
+
+// Attributes whose key contains one of these (case-insensitively) are always redacted.
+var sensitiveKeys = []string{"secret", "password", "passwd", "token", "authorization", "api_key", "apikey", "access_key", "credential"}
+
+var secrets struct {
+	mu     sync.RWMutex
+	values []string
+}
+
+// Registers credential values, such as the AWS keys read from the environment, to be replaced wherever they show up:
+// in messages, attribute values and formatted errors. Empty and very short values are ignored.
+func Redact(values ...string) {
+	secrets.mu.Lock()
+	defer secrets.mu.Unlock()
+	for _, value := range values {
+		if len(value) >= minSecretLength {
+			secrets.values = append(secrets.values, value)
+		}
+	}
+}
+
+// Builds a logger writing to w at the configured level, as JSON or as logfmt-style text.
+func New(settings config.Log, w io.Writer) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level(settings.Level), ReplaceAttr: redactAttr}
+	if settings.Format == config.LogFormatJSON {
+		return slog.New(slog.NewJSONHandler(w, options))
+	}
+	return slog.New(slog.NewTextHandler(w, options))
+}
+
+// Logs msg at the error level and exits, for failures the scrape can't carry on from.
+func Fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
+
+// Shorthand for the attribute failures are logged under.
+func Err(err error) slog.Attr {
+	return slog.Any("error", err)
+}
+
+func level(name string) slog.Level {
+	switch name {
+	case config.LogLevelDebug:
+		return slog.LevelDebug
+	case config.LogLevelWarn:
+		return slog.LevelWarn
+	case config.LogLevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Redacts sensitive keys outright and scrubs registered secrets from everything else, the message included.
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(attr.Key, Redacted)
+		}
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, scrub(value.String()))
+	case slog.KindAny:
+		// Errors and Stringers are formatted here so a credential inside them can't slip past as a non-string value.
+		switch v := value.Any().(type) {
+		case error:
+			return slog.String(attr.Key, scrub(v.Error()))
+		case fmt.Stringer:
+			return slog.String(attr.Key, scrub(v.String()))
+		}
+	}
+	return attr
+}
+
+func scrub(text string) string {
+	secrets.mu.RLock()
+	defer secrets.mu.RUnlock()
+	for _, secret := range secrets.values {
+		text = strings.ReplaceAll(text, secret, Redacted)
+	}
+	return text
+}
@@ -1,63 +1,376 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+	_ "time/tzdata" // timezone is resolved without relying on the host's zoneinfo, e.g. in scratch containers.
+
+	"github.com/joho/godotenv"
+	"github.com/mrovengerdev/vlrscrape/columnar"
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/logging"
+	"github.com/mrovengerdev/vlrscrape/metrics"
 	"github.com/mrovengerdev/vlrscrape/paginator"
 	"github.com/mrovengerdev/vlrscrape/restAPI"
+	"github.com/mrovengerdev/vlrscrape/retention"
 	"github.com/mrovengerdev/vlrscrape/s3port"
 	"github.com/mrovengerdev/vlrscrape/scrape"
-	"github.com/mrovengerdev/vlrscrape/scrapetools"
+	"github.com/mrovengerdev/vlrscrape/sink"
 )
 
 func main() {
+	configPath := flag.String("config", "", "path to a JSON config file (defaults are used when empty)")
+	hashAPIKey := flag.String("hash-api-key", "", "print the hash of an API key for server.access.api_keys and exit")
+	upload := flag.Bool("upload", false, "upload every file already in output_dir to the s3 sink's bucket and exit")
+	compact := flag.Bool("compact", false, "apply the retention policy to output_dir and the s3 sink's bucket and exit")
+	publish := flag.Bool("publish", false, "publish the static API for the output already in output_dir to the s3 sink's bucket and exit")
+	dryRun := flag.Bool("dry-run", false, "with -upload, -publish or -compact, list the changes without making them")
+	flag.Parse()
+
+	if *hashAPIKey != "" {
+		fmt.Println(config.HashAPIKey(*hashAPIKey))
+		return
+	}
+
+	// Environment variables in .env may override the config file, so load them first if present.
+	_ = godotenv.Load()
+
+	// Validate the whole configuration before any scraping starts.
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		logging.Fatal(logging.New(config.Default().Log, os.Stderr), "loading config failed", logging.Err(err))
+	}
+
+	// Every package logs through this logger. It's also the default, so stray log and slog calls get the same
+	// format and redaction. AWS credentials are registered up front so they can't show up in any line.
+	logger := logging.New(cfg.Log, os.Stderr)
+	slog.SetDefault(logger)
+	restAPI.SetLogger(logger)
+	restAPI.SetDisplayLocation(cfg.Location())
+	logging.Redact(os.Getenv(cfg.S3.AccessKeyEnv), os.Getenv(cfg.S3.SecretKeyEnv), os.Getenv("AWS_SECRET_ACCESS_KEY"), os.Getenv("AWS_SESSION_TOKEN"))
+
+	// Copies earlier output into the bucket, e.g. after adding the s3 sink to an existing setup.
+	if *upload {
+		if !cfg.HasSink(config.SinkS3) {
+			logging.Fatal(logger, "-upload needs the s3 sink in storage.sinks")
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		manifest, err := s3port.Upload(ctx, logger, cfg.S3, cfg.Sections, cfg.OutputDir, *dryRun)
+		stop()
+		if err != nil {
+			logging.Fatal(logger, "upload finished with errors", "failed", len(manifest.Failed), logging.Err(err))
+		}
+		return
+	}
+
+	// Thins out existing snapshots without scraping, e.g. to check a new retention policy with -dry-run.
+	if *compact {
+		if !cfg.Retention.Enabled {
+			logging.Fatal(logger, "-compact needs retention.enabled")
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		err := applyRetention(ctx, logger, cfg, *dryRun)
+		stop()
+		if err != nil {
+			logging.Fatal(logger, "retention finished with errors", logging.Err(err))
+		}
+		return
+	}
+
+	// Publishes the static API without scraping, e.g. to try s3.static before enabling it.
+	if *publish {
+		if !cfg.HasSink(config.SinkS3) {
+			logging.Fatal(logger, "-publish needs the s3 sink in storage.sinks")
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		manifest, err := publishStatic(ctx, logger, cfg, *dryRun)
+		stop()
+		if err != nil {
+			logging.Fatal(logger, "publishing static API finished with errors", "failed", len(manifest.Failed), logging.Err(err))
+		}
+		return
+	}
+
+	scrape.SetBaseURL(cfg.BaseURL)
+	scrape.SetSourceLocation(cfg.SourceLocation())
+	rankingDir := filepath.Join(cfg.OutputDir, cfg.Sections.Rankings.Output)
+
+	// Every output file is written to all of the configured sinks as soon as it's scraped.
+	sinks, err := newSinks(cfg)
+	if err != nil {
+		logging.Fatal(logger, "opening storage sinks failed", logging.Err(err))
+	}
+	// Writes are recorded so that each run can tell the sections that got no data from those that failed in part.
+	out := &sink.Recorder{Sink: sinks}
+	defer out.Close()
+	logger.Info("writing output", "sinks", out.Name())
+
+	// Scrapes every enabled section, tagging each line with the run and section it belongs to.
+	// A failing section doesn't stop the others; the errors are returned by section.
+	run := func(logger *slog.Logger) map[string]error {
+		errs := map[string]error{}
+
+		// Scrape from VLR.gg threads.
+		if section := cfg.Sections.Threads; section.Enabled {
+			start := time.Now()
+			if err := scrape.PageParser(logger.With("section", "threads"), cfg.SectionURL(config.ThreadsPath), section.Header, out, section.Output, newPaginator(cfg.RateLimit)); err != nil {
+				errs["threads"] = err
+			}
+			metrics.ObserveSince(metrics.ScrapeDuration, start, "threads")
+		}
+
+		// Scrape from VLR.gg matches.
+		if section := cfg.Sections.Matches; section.Enabled {
+			start := time.Now()
+			if err := scrape.PageParser(logger.With("section", "matches"), cfg.SectionURL(config.MatchesPath), section.Header, out, section.Output, newPaginator(cfg.RateLimit)); err != nil {
+				errs["matches"] = err
+			}
+			metrics.ObserveSince(metrics.ScrapeDuration, start, "matches")
+		}
 
-	// Creates output folder for JSON files.
-	scrapetools.CreateDirectory("output/ranking")
+		// Scrape from VLR.gg rankings.
+		if section := cfg.Sections.Rankings; section.Enabled {
+			start := time.Now()
+			sectionLogger := logger.With("section", "rankings")
+			prepDocument, err := scrape.ScrapePrep(sectionLogger.With("url", cfg.SectionURL(config.RankingsPath)), cfg.SectionURL(config.RankingsPath))
+			if err == nil {
+				err = scrape.AllRankingScrape(sectionLogger, prepDocument, cfg.SectionURL(config.RankingsPath), out, section.Output)
+			}
+			if err != nil {
+				errs["rankings"] = err
+			}
+			metrics.ObserveSince(metrics.ScrapeDuration, start, "rankings")
+		}
 
-	// Scrape from VLR.gg threads.
-	scrape.PageParser("https://www.vlr.gg/threads", "/?t=1w", "outputThreads", paginator.RestAPIPaginator())
+		return errs
+	}
 
-	// Scrape from VLR.gg matches.
-	scrape.PageParser("https://www.vlr.gg/matches", "/?", "outputMatches", paginator.RestAPIPaginator())
+	// Change events for WebSocket subscribers are diffed from the snapshots after every scrape.
+	sections := sectionOutputs(cfg)
+	changes := restAPI.NewChangeHub(restAPI.NewSnapshotIndex(cfg.OutputDir, sections), restAPI.NewRegionIndex(rankingDir))
+	if err := changes.Refresh(); err != nil {
+		logger.Error("diffing snapshots failed", logging.Err(err))
+	}
 
-	// Scrape from VLR.gg rankings.
-	prepDocument := scrape.ScrapePrep("https://www.vlr.gg/rankings")
-	scrape.AllRankingScrape(prepDocument)
+	// Interrupts and SIGTERM stop the schedule and shut the servers down gracefully.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Upload output files to Amazon S3 bucket: "vlr-scrape".
-	s3port.Upload()
+	// Each scrape is reported to /readyz, which counts the data as stale after three scheduled scrapes without every section.
+	status := restAPI.NewScrapeStatus(3 * cfg.Schedule.Interval.Duration)
+	// Runs are named after the time they started, in the same layout as the snapshot files.
+	scrapeAndRefresh := func() {
+		runLogger := logger.With("run", time.Now().UTC().Format(scrape.TimestampLayout))
+		runLogger.Info("scrape started")
+		status.Start()
+		out.BeginRun()
+		sectionErrs := run(runLogger)
+		var failed, degraded []string
+		for _, section := range enabledSections(cfg) {
+			err := sectionErrs[section]
+			if err != nil {
+				runLogger.Error("scrape finished with errors", "section", section, logging.Err(err))
+			}
+			switch {
+			case !sectionWritten(cfg, section, out.Written()):
+				failed = append(failed, section)
+			case err != nil:
+				degraded = append(degraded, section)
+			}
+		}
+		if cfg.Retention.Enabled {
+			if err := applyRetention(ctx, runLogger, cfg, false); err != nil {
+				runLogger.Error("applying retention failed", logging.Err(err))
+			}
+		}
+		if err := changes.Refresh(); err != nil {
+			runLogger.Error("diffing snapshots failed", logging.Err(err))
+			degraded = append(degraded, "changes")
+		}
+		status.Finish(failed, degraded)
+		if cfg.S3.Static.Enabled {
+			if _, err := publishStatic(ctx, runLogger, cfg, false); err != nil {
+				runLogger.Error("publishing static API failed", logging.Err(err))
+			}
+		}
+		runLogger.Info("scrape finished")
+	}
 
-	// Enables REST API endpoint throuhg localhost.
-	// Guide in restAPI.go file OR terminal.
-	restAPI.Get()
+	// Polls the first page of matches for the live match stream. Left nil when disabled so the
+	// stream isn't served and WatchMatches answers Unavailable, rather than waiting on a feed that never updates.
+	var liveFeed *restAPI.LiveFeed
+	if cfg.Live.Enabled {
+		liveFeed = restAPI.NewLiveFeed()
+		go liveFeed.Poll(ctx, cfg.Live.Interval.Duration, func() ([]scrape.Match, error) {
+			return scrape.LiveMatchScrape(cfg.SectionURL(config.MatchesPath))
+		})
+	}
+
+	// API keys and rate limits of server.access, shared by both APIs so each client has a single budget.
+	access := restAPI.NewAccessControl(cfg.Server.Access)
+
+	// Enables the gRPC API alongside the REST API. Definitions in vlrscrapepb/vlrscrape.proto.
+	grpcDone := make(chan struct{})
+	if cfg.GRPC.Enabled {
+		go func() {
+			defer close(grpcDone)
+			if err := restAPI.ServeGRPC(ctx, cfg.GRPC.Addr, cfg.Server.ShutdownTimeout.Duration, access, cfg.OutputDir, rankingDir, sections, liveFeed); err != nil {
+				logging.Fatal(logger, "gRPC API failed", logging.Err(err))
+			}
+		}()
+	} else {
+		close(grpcDone)
+	}
+
+	// Enables REST API endpoint through the configured address, started before the first scrape so
+	// /healthz answers right away while /readyz waits for the scrape to finish.
+	// Guide in restAPI.go file OR terminal with log.level set to debug.
+	serverDone := make(chan struct{})
+	if cfg.Server.Enabled {
+		server := restAPI.NewServer(cfg.Server, access, cfg.OutputDir, rankingDir, sections, liveFeed, changes, status)
+		go func() {
+			defer close(serverDone)
+			if err := server.Run(ctx); err != nil {
+				logging.Fatal(logger, "REST API failed", logging.Err(err))
+			}
+		}()
+	} else {
+		close(serverDone)
+	}
+
+	scrapeAndRefresh()
 
 	// Scheduled version of the main method.
-	// Scheduler runs the program at 6:00AM, 12:00PM, 6:00PM, and 12:00AM.
-	// c := cron.New()
-	// c.AddFunc("0 */6 * * *", func() {
+	// Re-runs the scrape every schedule.interval, e.g. "6h" for 6:00AM, 12:00PM, 6:00PM, and 12:00AM.
+	if interval := cfg.Schedule.Interval.Duration; interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+	schedule:
+		for {
+			select {
+			case <-ctx.Done():
+				break schedule
+			case <-ticker.C:
+				scrapeAndRefresh()
+			}
+		}
+	}
 
-	// 	// Creates output folder for JSON files.
-	// 	scrapetools.CreateOutputDirectory()
+	// Waits for the servers to shut down. Without a server or schedule the process exits after the scrape.
+	if !cfg.Server.Enabled && !cfg.GRPC.Enabled {
+		return
+	}
+	<-serverDone
+	<-grpcDone
+	logger.Info("shut down")
+}
 
-	// 	// Scrape from VLR.gg threads. Change 2nd argument to specify time frame.
-	// 	scrape.PageParser("https://www.vlr.gg/threads", "/?t=1w", "output_Threads")
+// Opens every sink listed in storage.sinks.
+func newSinks(cfg *config.Config) (sink.Multi, error) {
+	var sinks sink.Multi
+	for _, name := range cfg.Storage.Sinks {
+		var opened sink.Sink
+		var err error
+		switch name {
+		case config.SinkLocal:
+			opened = sink.NewLocal(cfg.OutputDir)
+		case config.SinkS3:
+			opened, err = s3port.NewSink(context.Background(), cfg.S3, cfg.Sections)
+		case config.SinkSQLite:
+			opened, err = sink.NewSQLite(cfg.SQLite.Path)
+		case config.SinkStdout:
+			opened = sink.NewStdout(os.Stdout)
+		case config.SinkParquet:
+			opened = columnar.NewSink(cfg.Parquet.Dir, cfg.Sections)
+		}
+		if err != nil {
+			sinks.Close()
+			return nil, fmt.Errorf("%s sink: %w", name, err)
+		}
+		sinks = append(sinks, opened)
+	}
+	return sinks, nil
+}
 
-	// 	// Scrape from VLR.gg matches.
-	// 	scrape.PageParser("https://www.vlr.gg/matches", "/?", "output_Matches")
+// Lists the enabled sections in the order they are scraped.
+func enabledSections(cfg *config.Config) []string {
+	var sections []string
+	for _, section := range []struct {
+		name     string
+		settings config.Section
+	}{{"threads", cfg.Sections.Threads}, {"matches", cfg.Sections.Matches}, {"rankings", cfg.Sections.Rankings}} {
+		if section.settings.Enabled {
+			sections = append(sections, section.name)
+		}
+	}
+	return sections
+}
 
-	// 	// Scrape from VLR.gg rankings.
-	// 	prepDocument := scrape.ScrapePrep("https://www.vlr.gg/rankings")
-	// 	scrape.WorldRankingScrape(prepDocument)
+// Reports whether a run that wrote the given output files got data for section.
+func sectionWritten(cfg *config.Config, section string, written []string) bool {
+	prefix := map[string]string{
+		"threads":  cfg.Sections.Threads.Output + "_",
+		"matches":  cfg.Sections.Matches.Output + "_",
+		"rankings": cfg.Sections.Rankings.Output + "/",
+	}[section]
+	return slices.ContainsFunc(written, func(name string) bool { return strings.HasPrefix(name, prefix) })
+}
+
+// Maps each enabled paged section to its output file prefix, e.g. "threads" to "outputThreads".
+func sectionOutputs(cfg *config.Config) map[string]string {
+	sections := map[string]string{}
+	if cfg.Sections.Threads.Enabled {
+		sections["threads"] = cfg.Sections.Threads.Output
+	}
+	if cfg.Sections.Matches.Enabled {
+		sections["matches"] = cfg.Sections.Matches.Output
+	}
+	return sections
+}
 
-	// 	// Upload output files to Amazon S3 bucket: "vlr-scrape".
-	// 	s3port.Upload()
+// Applies the retention policy to the local output and the s3 bucket, whichever of the two are sinks.
+func applyRetention(ctx context.Context, logger *slog.Logger, cfg *config.Config, dryRun bool) error {
+	var stores []retention.Store
+	if cfg.HasSink(config.SinkLocal) {
+		stores = append(stores, retention.NewLocal(cfg.OutputDir, sectionOutputs(cfg)))
+	}
+	if cfg.HasSink(config.SinkS3) {
+		store, err := s3port.NewRetentionStore(ctx, cfg.S3, cfg.Sections)
+		if err != nil {
+			return fmt.Errorf("s3 retention: %w", err)
+		}
+		stores = append(stores, store)
+	}
 
-	// 	// Enables REST API endpoint throuhg localhost.
-	// 	restAPI.Get()
-	// })
-	// c.Start()
+	var errs []error
+	for _, store := range stores {
+		if _, err := retention.Apply(ctx, logger, store, cfg.Retention, time.Now(), dryRun); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", store.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-// TODO:
-// 1. Create a paginator module/package
-// 2. Add it as an argument for all scrape functions
-// 3. Test and verify that it works.
+// Renders the static API from the local output and publishes it under s3.static.prefix.
+func publishStatic(ctx context.Context, logger *slog.Logger, cfg *config.Config, dryRun bool) (s3port.Manifest, error) {
+	tree, err := restAPI.StaticTree(cfg.OutputDir, filepath.Join(cfg.OutputDir, cfg.Sections.Rankings.Output), sectionOutputs(cfg))
+	if err != nil {
+		return s3port.Manifest{}, fmt.Errorf("rendering static API: %w", err)
+	}
+	return s3port.Publish(ctx, logger, cfg.S3, tree, dryRun)
+}
+
+// Builds a paginator for a single section from the configured rate limit.
+func newPaginator(limit config.RateLimit) *paginator.Paginator {
+	return paginator.NewPaginator(limit.RequestsPerSecond, limit.Burst, limit.Timeout.Duration)
+}
@@ -0,0 +1,104 @@
+// Package metrics holds the Prometheus collectors for scraping, uploads and the REST API,
+// exposed in the text format by Handler.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Holds every vlrscrape collector along with the Go runtime and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+// Scraping. Sections are threads, matches, rankings, match pages ("match") and the live match poll ("live").
+var (
+	ScrapeDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "vlrscrape_scrape_duration_seconds",
+		Help:    "Time taken to scrape a whole section.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12), // 1s to ~34m
+	}, []string{"section"})
+
+	FetchesTotal = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "vlrscrape_http_fetches_total",
+		Help: "Pages fetched from the scraped site by section and HTTP status, or \"error\" when no response arrived.",
+	}, []string{"section", "status"})
+
+	FetchDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "vlrscrape_http_fetch_duration_seconds",
+		Help:    "Time taken to fetch a page from the scraped site.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"section"})
+
+	ItemsParsed = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "vlrscrape_items_parsed_total",
+		Help: "Threads, matches and rankings parsed by section.",
+	}, []string{"section"})
+
+	ParseErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "vlrscrape_parse_errors_total",
+		Help: "Items skipped because they couldn't be parsed, by section.",
+	}, []string{"section"})
+
+	RateLimiterWait = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "vlrscrape_rate_limiter_wait_seconds",
+		Help:    "Time spent waiting on the paginator's rate limiter before each request.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 4, 8), // 1ms to ~16s
+	}, []string{"section"})
+)
+
+// Uploads to Amazon S3.
+var (
+	UploadBytes = factory.NewCounter(prometheus.CounterOpts{
+		Name: "vlrscrape_s3_upload_bytes_total",
+		Help: "Bytes uploaded to Amazon S3.",
+	})
+
+	UploadDuration = factory.NewHistogram(prometheus.HistogramOpts{
+		Name:    "vlrscrape_s3_upload_duration_seconds",
+		Help:    "Time taken to upload a file to Amazon S3.",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	UploadFailures = factory.NewCounter(prometheus.CounterOpts{
+		Name: "vlrscrape_s3_upload_failures_total",
+		Help: "Files that failed to upload to Amazon S3.",
+	})
+)
+
+// REST API. Routes are the ServeMux patterns, e.g. "GET /teams/{slug}", so path values don't inflate the label set.
+var RequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "vlrscrape_http_request_duration_seconds",
+	Help:    "Latency of REST API requests by route, method and status code.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"route", "method", "code"})
+
+// gRPC API. Methods are the full names, e.g. "/vlrscrape.v1.VLRScrape/GetThread", and codes the status code names.
+var GRPCRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "vlrscrape_grpc_request_duration_seconds",
+	Help:    "Latency of gRPC API calls by method and status code. Streams are observed when they end.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"method", "code"})
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Serves every metric in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Records the time elapsed since start in a histogram with the given labels.
+func ObserveSince(histogram *prometheus.HistogramVec, start time.Time, labels ...string) {
+	histogram.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// JSON body returned for every failed request.
+type APIError struct {
+	Status  int    `json:"status"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// Position of a page within the items of a paged endpoint.
+type PageMeta struct {
+	Total      int    `json:"total"`  // Items matching the filters before pagination.
+	Count      int    `json:"count"`  // Items in this page.
+	Offset     int    `json:"offset"` // Position of the first item in this page.
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"` // Pass as ?cursor= to fetch the next page of the same snapshot; empty on the last page.
+}
+
+// A single timestamped output file of a section, as listed by /{section}/snapshots.
+type Snapshot struct {
+	Section   string    `json:"section"`
+	Timestamp string    `json:"timestamp"`
+	ScrapedAt time.Time `json:"scraped_at"`
+	File      string    `json:"file"`
+	URL       string    `json:"url"`
+}
+
+// A match with the current ranking of each team, where the team is ranked in a scraped region.
+type MatchDetail struct {
+	Match
+	Team1Ranking *Ranking `json:"team1_ranking,omitempty"`
+	Team2Ranking *Ranking `json:"team2_ranking,omitempty"`
+}
+
+// A team assembled from its regional rankings and the matches it appears in.
+type Team struct {
+	Slug     string    `json:"slug"`
+	Name     string    `json:"name"`
+	TeamURL  string    `json:"team_url"`
+	Rankings []Ranking `json:"rankings"`
+	Matches  []Match   `json:"matches"`
+}
@@ -0,0 +1,101 @@
+// Package model holds the documents the APIs serve: the scraped threads, matches and rankings, and the REST API's
+// response types. It depends on nothing else in the module, so API clients can use it without the scraper or server.
+package model
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type Thread struct {
+	ID               int    `json:"id"`
+	Title            string `json:"title"`
+	ThreadURL        string `json:"thread_url"`
+	FragCount        int    `json:"frag_count"`
+	DatePublished    string `json:"date_published"`
+	DatePublishedAgo string `json:"date_published_ago"`
+	CommentCount     int    `json:"comment_count"`
+	// When the thread was posted, in UTC, parsed from DatePublished or estimated from DatePublishedAgo at scrape time;
+	// nil when neither can be read. DatePublished and DatePublishedAgo are kept as scraped.
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	// Derived from PublishedAt whenever the thread is served, see At. Never stored in snapshots.
+	AgeHours        *float64 `json:"age_hours,omitempty"`
+	FragsPerHour    *float64 `json:"frags_per_hour,omitempty"`
+	CommentsPerHour *float64 `json:"comments_per_hour,omitempty"`
+}
+
+type Match struct {
+	ID             int    `json:"id"`
+	MatchURL       string `json:"match_url"`
+	Tournament     string `json:"tournament"`
+	Team1          string `json:"team1"`
+	Team2          string `json:"team2"`
+	Score1         string `json:"score1,omitempty"` // Empty until the match has started
+	Score2         string `json:"score2,omitempty"`
+	Date           string `json:"date"`
+	MatchTime      string `json:"match_time"`
+	TimeUntilMatch string `json:"time_until_match"` // Time until match
+	// Start time in UTC from the match page's data-utc-ts, nil when the page doesn't give one. Snapshots keep Date,
+	// MatchTime and TimeUntilMatch as scraped, and At renders them from ScheduledAt when they are displayed.
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+}
+
+type Ranking struct {
+	Rank     int    `json:"rank"`
+	Region   string `json:"region"`
+	TeamName string `json:"team_name"`
+	ELO      int    `json:"elo"`
+	TeamURL  string `json:"team_url"`
+}
+
+// Returns the thread as served at now, with AgeHours, FragsPerHour and CommentsPerHour derived from PublishedAt and
+// rounded to four decimals. Threads younger than an hour are rated per hour as if they were an hour old, so a new
+// thread doesn't top the trending list with its first frag. Threads without PublishedAt are returned as scraped.
+func (thread Thread) At(now time.Time) Thread {
+	if thread.PublishedAt == nil {
+		return thread
+	}
+	age := max(now.Sub(*thread.PublishedAt).Hours(), 0)
+	hours := max(age, 1)
+	thread.AgeHours = roundedRate(age, 1)
+	thread.FragsPerHour = roundedRate(float64(thread.FragCount), hours)
+	thread.CommentsPerHour = roundedRate(float64(thread.CommentCount), hours)
+	return thread
+}
+
+func roundedRate(count float64, hours float64) *float64 {
+	rate := math.Round(count/hours*10000) / 10000
+	return &rate
+}
+
+// Returns the match as displayed at now in zone. When ScheduledAt is known, Date and MatchTime are rendered from it,
+// e.g. "Saturday, November 9, 2024" and "7:00 PM CET", and TimeUntilMatch is counted down from now instead of from
+// the scrape. Live matches keep "Live", and matches without ScheduledAt are returned as scraped.
+func (match Match) At(now time.Time, zone *time.Location) Match {
+	if match.ScheduledAt == nil {
+		return match
+	}
+	local := match.ScheduledAt.In(zone)
+	match.Date = local.Format("Monday, January 2, 2006")
+	match.MatchTime = local.Format("3:04 PM MST")
+	if match.TimeUntilMatch != "Live" {
+		match.TimeUntilMatch = formatETA(match.ScheduledAt.Sub(now))
+	}
+	return match
+}
+
+// Formats the time left until a match the way vlr.gg does, e.g. "1d 5h", "2h 30m" or "45m". Matches past their
+// start time that haven't gone live show "0m".
+func formatETA(remaining time.Duration) string {
+	minutes := max(int(remaining.Minutes()), 0)
+	days, hours := minutes/(24*60), minutes/60%24
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes%60)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestThreadAt(t *testing.T) {
+	now := time.Date(2024, 11, 7, 15, 0, 0, 0, time.UTC)
+	at := func(hoursAgo float64) *time.Time {
+		published := now.Add(-time.Duration(hoursAgo * float64(time.Hour)))
+		return &published
+	}
+	format := func(value *float64) string {
+		if value == nil {
+			return "nil"
+		}
+		return fmt.Sprint(*value)
+	}
+	tests := []struct {
+		name     string
+		thread   Thread
+		age      string
+		frags    string
+		comments string
+	}{
+		{name: "not published", thread: Thread{FragCount: 10, CommentCount: 4}, age: "nil", frags: "nil", comments: "nil"},
+		{name: "two hours", thread: Thread{FragCount: 10, CommentCount: 4, PublishedAt: at(2)}, age: "2", frags: "5", comments: "2"},
+		{name: "rounded", thread: Thread{FragCount: 10, CommentCount: 1, PublishedAt: at(3)}, age: "3", frags: "3.3333", comments: "0.3333"},
+		{name: "younger than an hour", thread: Thread{FragCount: 6, CommentCount: 3, PublishedAt: at(0.25)}, age: "0.25", frags: "6", comments: "3"},
+		{name: "in the future", thread: Thread{FragCount: 6, PublishedAt: at(-1)}, age: "0", frags: "6", comments: "0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.thread.At(now)
+			if age := format(got.AgeHours); age != test.age {
+				t.Errorf("age_hours = %s, want %s", age, test.age)
+			}
+			if frags := format(got.FragsPerHour); frags != test.frags {
+				t.Errorf("frags_per_hour = %s, want %s", frags, test.frags)
+			}
+			if comments := format(got.CommentsPerHour); comments != test.comments {
+				t.Errorf("comments_per_hour = %s, want %s", comments, test.comments)
+			}
+			if test.thread.AgeHours != nil {
+				t.Error("At changed the receiver")
+			}
+		})
+	}
+}
+
+func TestFormatETA(t *testing.T) {
+	tests := []struct {
+		remaining time.Duration
+		want      string
+	}{
+		{45 * time.Minute, "45m"},
+		{59*time.Minute + 59*time.Second, "59m"},
+		{time.Hour, "1h 0m"},
+		{2*time.Hour + 30*time.Minute, "2h 30m"},
+		{24 * time.Hour, "1d 0h"},
+		{29*time.Hour + 10*time.Minute, "1d 5h"},
+		{0, "0m"},
+		{-time.Hour, "0m"},
+	}
+	for _, test := range tests {
+		if got := formatETA(test.remaining); got != test.want {
+			t.Errorf("formatETA(%v) = %q, want %q", test.remaining, got, test.want)
+		}
+	}
+}
+
+func TestMatchAt(t *testing.T) {
+	now := time.Date(2024, 11, 9, 16, 0, 0, 0, time.UTC)
+	scheduled := time.Date(2024, 11, 9, 18, 0, 0, 0, time.UTC)
+	cet := time.FixedZone("CET", 3600)
+	tests := []struct {
+		name  string
+		match Match
+		want  Match
+	}{
+		{
+			name:  "scheduled",
+			match: Match{Date: "scraped", MatchTime: "scraped", TimeUntilMatch: "5h 0m", ScheduledAt: &scheduled},
+			want:  Match{Date: "Saturday, November 9, 2024", MatchTime: "7:00 PM CET", TimeUntilMatch: "2h 0m", ScheduledAt: &scheduled},
+		},
+		{
+			name:  "live",
+			match: Match{TimeUntilMatch: "Live", ScheduledAt: &scheduled},
+			want:  Match{Date: "Saturday, November 9, 2024", MatchTime: "7:00 PM CET", TimeUntilMatch: "Live", ScheduledAt: &scheduled},
+		},
+		{
+			name:  "unscheduled",
+			match: Match{Date: "TBD", MatchTime: "TBD", TimeUntilMatch: "TBD"},
+			want:  Match{Date: "TBD", MatchTime: "TBD", TimeUntilMatch: "TBD"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.match.At(now, cet); got != test.want {
+				t.Errorf("At = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
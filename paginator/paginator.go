@@ -3,6 +3,7 @@ package paginator
 import (
 	"time"
 
+	"github.com/mrovengerdev/vlrscrape/metrics"
 	"golang.org/x/net/context"
 	"golang.org/x/time/rate"
 )
@@ -16,9 +17,14 @@ type Paginator struct {
 // TODO: Rewrite so cancel occurs upon 100 requests.
 // Enforces paginator that limits requests to 10 per second and cancels out if it takes longer than 30 seconds.
 func RestAPIPaginator() (paginator *Paginator) {
-	limiter := rate.NewLimiter(10, 1) // 10 requests per second, with a burst of 1 request.
+	return NewPaginator(10, 1, 30*time.Second) // 10 requests per second, with a burst of 1 request.
+}
+
+// Enforces paginator with the given request rate and burst that cancels out once timeout has elapsed.
+func NewPaginator(requestsPerSecond float64, burst int, timeout time.Duration) (paginator *Paginator) {
+	limiter := rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 
 	return &Paginator{
 		Limiter: limiter,
@@ -26,3 +32,12 @@ func RestAPIPaginator() (paginator *Paginator) {
 		Cancel:  cancel,
 	}
 }
+
+// Waits for permission from the limiter before requesting a page of section, recording the time spent waiting.
+// Fails once the paginator's timeout has elapsed.
+func (paginator *Paginator) Wait(section string) error {
+	start := time.Now()
+	err := paginator.Limiter.Wait(paginator.Context)
+	metrics.ObserveSince(metrics.RateLimiterWait, start, section)
+	return err
+}
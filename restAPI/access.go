@@ -0,0 +1,244 @@
+package restAPI
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Clients idle for this long lose their token bucket, so the table of IP addresses doesn't grow without bound.
+// A returning client starts again with a full bucket.
+const clientIdleTimeout = 10 * time.Minute
+
+// Authenticates API keys and rate limits each client with its own token bucket.
+type AccessControl struct {
+	requireKey bool
+	keys       map[string]apiKey // Keyed by the hex SHA-256 of the key.
+	ipLimit    config.ClientLimit
+
+	mu        sync.Mutex
+	clients   map[string]*clientBucket
+	lastSweep time.Time
+}
+
+type apiKey struct {
+	name  string
+	limit config.ClientLimit
+}
+
+type clientBucket struct {
+	limiter  *rate.Limiter
+	burst    int
+	lastSeen time.Time
+}
+
+func NewAccessControl(settings config.Access) *AccessControl {
+	access := &AccessControl{
+		requireKey: settings.RequireAPIKey,
+		keys:       map[string]apiKey{},
+		ipLimit:    settings.IPLimit,
+		clients:    map[string]*clientBucket{},
+		lastSweep:  time.Now(),
+	}
+	for _, key := range settings.APIKeys {
+		limit := settings.KeyLimit
+		if key.Limit != nil {
+			limit = *key.Limit
+		}
+		access.keys[strings.ToLower(key.SHA256)] = apiKey{name: key.Name, limit: limit}
+	}
+	return access
+}
+
+// Outcome of admitting a request. Refused requests have a status of 401 or 429 and a message; the bucket
+// fields are only set once the client is known, so not for rejected keys.
+type admission struct {
+	status     int
+	message    string
+	limited    bool // Whether the bucket fields below are set.
+	burst      int
+	remaining  int
+	reset      int // Seconds until the bucket is full again.
+	retryAfter int // Seconds until a rate limited client may try again.
+}
+
+// Authenticates key, when one was sent, and takes a token from the bucket of the client, which is the key
+// or else the IP address. Shared by the REST API and the gRPC interceptors so both draw from the same buckets.
+func (access *AccessControl) admit(key string, ip string) admission {
+	client, limit := "", access.ipLimit
+	if key != "" {
+		known, ok := access.keys[config.HashAPIKey(key)]
+		if !ok {
+			return admission{status: http.StatusUnauthorized, message: "unknown API key"}
+		}
+		client, limit = "key:"+known.name, known.limit
+	} else if access.requireKey {
+		return admission{status: http.StatusUnauthorized, message: "an API key is required, send it as X-API-Key or Authorization: Bearer"}
+	} else {
+		client = "ip:" + ip
+	}
+
+	now := time.Now()
+	bucket := access.bucket(client, limit, now)
+	allowed := bucket.limiter.AllowN(now, 1)
+	tokens := bucket.limiter.TokensAt(now)
+
+	rps := float64(bucket.limiter.Limit())
+	result := admission{
+		limited:   true,
+		burst:     bucket.burst,
+		remaining: max(0, int(math.Floor(tokens))),
+		reset:     int(math.Ceil((float64(bucket.burst) - tokens) / rps)),
+	}
+	if !allowed {
+		result.status = http.StatusTooManyRequests
+		result.message = fmt.Sprintf("rate limit of %g requests per second exceeded", rps)
+		result.retryAfter = max(1, int(math.Ceil((1-tokens)/rps)))
+	}
+	return result
+}
+
+// Wraps next with API key authentication and rate limiting. Keys are sent as "X-API-Key: <key>" or
+// "Authorization: Bearer <key>". Unknown keys are always rejected, and missing keys when they are required.
+// Every response carries X-RateLimit-Limit (the bucket size), X-RateLimit-Remaining and X-RateLimit-Reset
+// (seconds until the bucket is full again); limited requests get a 429 with Retry-After.
+func (access *AccessControl) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result := access.admit(requestAPIKey(r), clientIP(r))
+		if result.limited {
+			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.burst))
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.remaining))
+			w.Header().Set("X-RateLimit-Reset", strconv.Itoa(result.reset))
+		}
+		switch result.status {
+		case http.StatusUnauthorized:
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		case http.StatusTooManyRequests:
+			w.Header().Set("Retry-After", strconv.Itoa(result.retryAfter))
+		}
+		if result.status != 0 {
+			writeError(w, result.status, result.message)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Applies the same authentication and rate limits as Wrap to unary gRPC calls. Keys are read from the
+// x-api-key or authorization metadata, and the rate limit headers are sent as x-ratelimit-* metadata.
+func (access *AccessControl) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		header, err := access.admitCall(ctx)
+		grpc.SetHeader(ctx, header)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, request)
+	}
+}
+
+// Stream counterpart of UnaryInterceptor. A stream takes a single token when it is opened.
+func (access *AccessControl) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		header, err := access.admitCall(stream.Context())
+		stream.SetHeader(header)
+		if err != nil {
+			return err
+		}
+		return handler(server, stream)
+	}
+}
+
+// Admits a gRPC call, returning the rate limit metadata to send and the status error of a refused call.
+func (access *AccessControl) admitCall(ctx context.Context) (metadata.MD, error) {
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	key := ""
+	if values := incoming.Get("x-api-key"); len(values) > 0 {
+		key = values[0]
+	} else if values := incoming.Get("authorization"); len(values) > 0 {
+		if scheme, token, ok := strings.Cut(values[0], " "); ok && strings.EqualFold(scheme, "Bearer") {
+			key = strings.TrimSpace(token)
+		}
+	}
+	ip := ""
+	if client, ok := peer.FromContext(ctx); ok {
+		ip = client.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+
+	result := access.admit(key, ip)
+	header := metadata.MD{}
+	if result.limited {
+		header.Set("x-ratelimit-limit", strconv.Itoa(result.burst))
+		header.Set("x-ratelimit-remaining", strconv.Itoa(result.remaining))
+		header.Set("x-ratelimit-reset", strconv.Itoa(result.reset))
+	}
+	switch result.status {
+	case http.StatusUnauthorized:
+		return header, status.Error(codes.Unauthenticated, result.message)
+	case http.StatusTooManyRequests:
+		header.Set("retry-after", strconv.Itoa(result.retryAfter))
+		return header, status.Error(codes.ResourceExhausted, result.message)
+	}
+	return header, nil
+}
+
+// Returns the token bucket of a client, creating it on first use and dropping idle ones along the way.
+func (access *AccessControl) bucket(client string, limit config.ClientLimit, now time.Time) *clientBucket {
+	access.mu.Lock()
+	defer access.mu.Unlock()
+
+	if now.Sub(access.lastSweep) > clientIdleTimeout {
+		for name, bucket := range access.clients {
+			if now.Sub(bucket.lastSeen) > clientIdleTimeout {
+				delete(access.clients, name)
+			}
+		}
+		access.lastSweep = now
+	}
+
+	bucket, ok := access.clients[client]
+	if !ok {
+		bucket = &clientBucket{limiter: rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.Burst), burst: limit.Burst}
+		access.clients[client] = bucket
+	}
+	bucket.lastSeen = now
+	return bucket
+}
+
+// Returns the API key sent with a request, or an empty string.
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// Returns the IP address a request came from. Forwarding headers are ignored since any client can set them.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
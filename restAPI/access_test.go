@@ -0,0 +1,107 @@
+package restAPI
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+)
+
+func TestAccessControl(t *testing.T) {
+	// Buckets refill too slowly to matter during the test.
+	slow := func(burst int) config.ClientLimit { return config.ClientLimit{RequestsPerSecond: 0.001, Burst: burst} }
+	vip := slow(3)
+	settings := config.Access{
+		APIKeys: []config.APIKey{
+			{Name: "default", SHA256: config.HashAPIKey("secret")},
+			{Name: "vip", SHA256: config.HashAPIKey("VIP"), Limit: &vip},
+		},
+		KeyLimit: slow(1),
+		IPLimit:  slow(2),
+	}
+	required := settings
+	required.RequireAPIKey = true
+
+	type request struct {
+		remoteAddr string
+		header     [2]string
+		status     int
+		limit      string // X-RateLimit-Limit, empty when it shouldn't be sent.
+		remaining  string
+	}
+	tests := []struct {
+		name     string
+		settings config.Access
+		requests []request
+	}{
+		{
+			name:     "per IP without keys",
+			settings: settings,
+			requests: []request{
+				{remoteAddr: "192.0.2.1:1000", status: 200, limit: "2", remaining: "1"},
+				{remoteAddr: "192.0.2.1:1001", status: 200, limit: "2", remaining: "0"},
+				{remoteAddr: "192.0.2.1:1002", status: 429, limit: "2", remaining: "0"},
+				{remoteAddr: "192.0.2.2:1000", status: 200, limit: "2", remaining: "1"},
+				// Forwarding headers can't pick another bucket.
+				{remoteAddr: "192.0.2.1:1003", header: [2]string{"X-Forwarded-For", "192.0.2.9"}, status: 429, limit: "2", remaining: "0"},
+			},
+		},
+		{
+			name:     "keys",
+			settings: settings,
+			requests: []request{
+				{remoteAddr: "192.0.2.1:1000", header: [2]string{"X-API-Key", "secret"}, status: 200, limit: "1", remaining: "0"},
+				// The key's bucket is shared across addresses and header styles.
+				{remoteAddr: "192.0.2.2:1000", header: [2]string{"Authorization", "bearer secret"}, status: 429, limit: "1", remaining: "0"},
+				{remoteAddr: "192.0.2.1:1000", header: [2]string{"X-API-Key", "VIP"}, status: 200, limit: "3", remaining: "2"},
+				{remoteAddr: "192.0.2.1:1000", status: 200, limit: "2", remaining: "1"},
+				{remoteAddr: "192.0.2.1:1000", header: [2]string{"X-API-Key", "guess"}, status: 401},
+				{remoteAddr: "192.0.2.1:1000", header: [2]string{"Authorization", "Basic c2VjcmV0"}, status: 200, limit: "2", remaining: "0"},
+			},
+		},
+		{
+			name:     "required keys",
+			settings: required,
+			requests: []request{
+				{remoteAddr: "192.0.2.1:1000", status: 401},
+				{remoteAddr: "192.0.2.1:1000", header: [2]string{"X-API-Key", "guess"}, status: 401},
+				{remoteAddr: "192.0.2.1:1000", header: [2]string{"Authorization", "Bearer VIP"}, status: 200, limit: "3", remaining: "2"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := NewAccessControl(test.settings).Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			for i, step := range test.requests {
+				r := httptest.NewRequest(http.MethodGet, "/threads", nil)
+				r.RemoteAddr = step.remoteAddr
+				if step.header[0] != "" {
+					r.Header.Set(step.header[0], step.header[1])
+				}
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+
+				if w.Code != step.status {
+					t.Errorf("request %d: status %d, want %d", i, w.Code, step.status)
+				}
+				if got := w.Header().Get("X-RateLimit-Limit"); got != step.limit {
+					t.Errorf("request %d: X-RateLimit-Limit %q, want %q", i, got, step.limit)
+				}
+				if got := w.Header().Get("X-RateLimit-Remaining"); got != step.remaining {
+					t.Errorf("request %d: X-RateLimit-Remaining %q, want %q", i, got, step.remaining)
+				}
+				switch step.status {
+				case http.StatusUnauthorized:
+					if w.Header().Get("WWW-Authenticate") != "Bearer" {
+						t.Errorf("request %d: 401 without WWW-Authenticate: Bearer", i)
+					}
+				case http.StatusTooManyRequests:
+					if w.Header().Get("Retry-After") == "" {
+						t.Errorf("request %d: 429 without Retry-After", i)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package restAPI
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Bodies smaller than this are sent uncompressed since the encoding overhead outweighs the savings.
+const minCompressSize = 1024
+
+// Collects a handler's response so it can be validated and compressed before anything is sent.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (response *bufferedResponse) Header() http.Header {
+	return response.header
+}
+
+func (response *bufferedResponse) WriteHeader(status int) {
+	response.status = status
+}
+
+func (response *bufferedResponse) Write(data []byte) (int, error) {
+	return response.body.Write(data)
+}
+
+// Wraps mux with HTTP caching and compression for GET and HEAD requests:
+// successful responses get an ETag computed from their content, conditional requests matching the ETag
+// or the Last-Modified header set by the handler are answered with a 304, and bodies are compressed
+// with br or gzip when the client accepts it. Routes whose pattern is in streaming are passed through.
+func withCaching(mux *http.ServeMux, streaming map[string]bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		if _, pattern := mux.Handler(r); streaming[pattern] {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		response := &bufferedResponse{header: http.Header{}, status: http.StatusOK}
+		mux.ServeHTTP(response, r)
+
+		for key, values := range response.header {
+			w.Header()[key] = values
+		}
+		body := response.body.Bytes()
+		// Set before the conditional check since a 304 must carry the Vary of the response it stands for.
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if response.status == http.StatusOK {
+			// Weak since the same ETag is sent for every Content-Encoding of the body.
+			sum := sha256.Sum256(body)
+			etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+			w.Header().Set("ETag", etag)
+
+			if notModified(r, etag, w.Header().Get("Last-Modified")) {
+				w.Header().Del("Content-Type")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+
+		if encoding := acceptedEncoding(r.Header.Get("Accept-Encoding")); encoding != "" && len(body) >= minCompressSize {
+			compressed, err := compress(body, encoding)
+			if err == nil {
+				w.Header().Set("Content-Encoding", encoding)
+				body = compressed
+			}
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(response.status)
+		if r.Method != http.MethodHead {
+			w.Write(body)
+		}
+	})
+}
+
+// Evaluates If-None-Match, or If-Modified-Since when no ETags were sent, against the current representation.
+func notModified(r *http.Request, etag string, lastModified string) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// Picks br or gzip from an Accept-Encoding header, preferring br when both are equally acceptable.
+func acceptedEncoding(acceptEncoding string) string {
+	best, bestQuality := "", 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "br" && name != "gzip" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality > bestQuality || (quality == bestQuality && name == "br") {
+			best, bestQuality = name, quality
+		}
+	}
+	return best
+}
+
+// Encodes body with the given Content-Encoding.
+func compress(body []byte, encoding string) ([]byte, error) {
+	var buffer bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case "br":
+		writer = brotli.NewWriterLevel(&buffer, brotli.DefaultCompression)
+	default:
+		writer = gzip.NewWriter(&buffer)
+	}
+
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// Sets the Last-Modified header used for If-Modified-Since checks.
+func setLastModified(w http.ResponseWriter, modified time.Time) {
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+}
@@ -0,0 +1,137 @@
+package restAPI
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCachingVariesOnAcceptEncoding(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []string{"a", "b"})
+	})
+	handler := withCaching(mux, nil)
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest("GET", "/", nil))
+	etag := response.Header().Get("ETag")
+	if response.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d with ETag %q, want 200 with an ETag", response.Code, etag)
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		status      int
+	}{
+		{"fresh", "", http.StatusOK},
+		{"matching ETag", etag, http.StatusNotModified},
+		{"other ETag", `W/"other"`, http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "/", nil)
+			if test.ifNoneMatch != "" {
+				request.Header.Set("If-None-Match", test.ifNoneMatch)
+			}
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, request)
+
+			if response.Code != test.status {
+				t.Errorf("status = %d, want %d", response.Code, test.status)
+			}
+			if vary := response.Header().Values("Vary"); len(vary) != 1 || vary[0] != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want exactly Accept-Encoding", vary)
+			}
+		})
+	}
+}
+
+func TestLastModified(t *testing.T) {
+	handler, _ := newTestHandler(t)
+	defer func(original func() time.Time) { timeNow = original }(timeNow)
+	now := time.Date(2024, 11, 7, 12, 0, 40, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	displayed := now.Truncate(time.Minute)
+	scraped := time.Date(2024, 11, 6, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		path         string
+		lastModified time.Time // Zero when the response carries only an ETag.
+	}{
+		{"/threads", displayed},
+		{"/threads/at/2024-11-06_15-04-05", displayed},
+		{"/matches", displayed},
+		{"/threads/10", time.Time{}},
+		{"/matches/101", time.Time{}},
+		{"/teams/fnatic", time.Time{}},
+		{"/threads/snapshots", scraped},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, httptest.NewRequest("GET", test.path, nil))
+			lastModified := response.Header().Get("Last-Modified")
+			if test.lastModified.IsZero() {
+				if lastModified != "" {
+					t.Errorf("Last-Modified = %q, want none", lastModified)
+				}
+				return
+			}
+			if want := test.lastModified.Format(http.TimeFormat); lastModified != want {
+				t.Fatalf("Last-Modified = %q, want %q", lastModified, want)
+			}
+
+			// A copy from earlier in the interval is current, one from before it is not.
+			for since, status := range map[time.Time]int{test.lastModified: http.StatusNotModified, test.lastModified.Add(-time.Second): http.StatusOK} {
+				request := httptest.NewRequest("GET", test.path, nil)
+				request.Header.Set("If-Modified-Since", since.Format(http.TimeFormat))
+				response := httptest.NewRecorder()
+				handler.ServeHTTP(response, request)
+				if response.Code != status {
+					t.Errorf("If-Modified-Since %s: status = %d, want %d", since.Format(http.TimeFormat), response.Code, status)
+				}
+			}
+		})
+	}
+}
+
+func TestDisplayedResponsesValidateWithinDisplayInterval(t *testing.T) {
+	handler, _ := newTestHandler(t)
+	defer func(original func() time.Time) { timeNow = original }(timeNow)
+	at := func(now time.Time) { timeNow = func() time.Time { return now } }
+	start := time.Date(2024, 11, 7, 12, 0, 10, 0, time.UTC)
+
+	for _, path := range []string{"/threads", "/matches", "/threads/10", "/matches/102"} {
+		t.Run(path, func(t *testing.T) {
+			at(start)
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, httptest.NewRequest("GET", path, nil))
+			etag := response.Header().Get("ETag")
+			if response.Code != http.StatusOK || etag == "" {
+				t.Fatalf("status = %d with ETag %q, want 200 with an ETag", response.Code, etag)
+			}
+
+			tests := []struct {
+				name   string
+				now    time.Time
+				status int
+			}{
+				{"same interval", start.Add(45 * time.Second), http.StatusNotModified},
+				{"next interval", start.Add(time.Minute), http.StatusOK},
+			}
+			for _, test := range tests {
+				at(test.now)
+				request := httptest.NewRequest("GET", path, nil)
+				request.Header.Set("If-None-Match", etag)
+				response := httptest.NewRecorder()
+				handler.ServeHTTP(response, request)
+				if response.Code != test.status {
+					t.Errorf("%s: status = %d, want %d", test.name, response.Code, test.status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package restAPI
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+// Types of ChangeEvent.
+const (
+	ChangeCreated = "created"
+	ChangeUpdated = "updated"
+	ChangeRemoved = "removed"
+)
+
+// A difference between two consecutive scrapes, published to subscribers of its topic.
+// Topics are "threads:new", "thread:{id}", "matches:new", "match:{id}" and "rankings:{region}".
+type ChangeEvent struct {
+	Topic    string    `json:"topic"`
+	Type     string    `json:"type"`
+	Time     time.Time `json:"time"`
+	Data     any       `json:"data"`
+	Previous any       `json:"previous,omitempty"`
+}
+
+// Diffs the newest snapshots against the previous ones on every Refresh and fans the resulting
+// change events out to subscribers.
+type ChangeHub struct {
+	snapshots *SnapshotIndex
+	regions   *RegionIndex
+
+	mu          sync.Mutex
+	loaded      bool
+	threads     map[int]scrape.Thread
+	matches     map[int]scrape.Match
+	rankings    map[string][]scrape.Ranking
+	subscribers map[*subscriber]struct{}
+}
+
+// Receives the events of the topics it is subscribed to. Events are dropped and the subscriber
+// closed when it falls more than its buffer behind.
+type subscriber struct {
+	topics map[string]bool
+	events chan ChangeEvent
+	closed bool
+}
+
+func NewChangeHub(snapshots *SnapshotIndex, regions *RegionIndex) *ChangeHub {
+	return &ChangeHub{
+		snapshots:   snapshots,
+		regions:     regions,
+		subscribers: map[*subscriber]struct{}{},
+	}
+}
+
+// Reports whether topic is one the hub can publish to.
+func validTopic(topic string) bool {
+	kind, key, found := strings.Cut(topic, ":")
+	if !found {
+		return false
+	}
+	switch kind {
+	case "threads", "matches":
+		return key == "new"
+	case "thread", "match":
+		return key != "" && strings.Trim(key, "0123456789") == ""
+	case "rankings":
+		return validName(key)
+	default:
+		return false
+	}
+}
+
+// Returns a thread without the fields that are relative to its scrape: DatePublishedAgo, and PublishedAt, which may
+// be estimated from it. Two scrapes of an unchanged thread compare equal.
+func threadContent(thread scrape.Thread) scrape.Thread {
+	thread.DatePublishedAgo = ""
+	thread.PublishedAt = nil
+	return thread
+}
+
+// Returns a match without its countdown, which changes with every scrape, keeping "Live" since a match going live is
+// a change. Two scrapes of an unchanged match compare equal.
+func matchContent(match scrape.Match) scrape.Match {
+	if match.TimeUntilMatch != "Live" {
+		match.TimeUntilMatch = ""
+	}
+	return match
+}
+
+// Loads the newest snapshots and rankings and publishes how they differ from the previous Refresh.
+// The first call only records the current state.
+func (hub *ChangeHub) Refresh() error {
+	threads, err := latestByID(hub.snapshots, "threads", func(thread scrape.Thread) int { return thread.ID })
+	if err != nil {
+		return err
+	}
+	matches, err := latestByID(hub.snapshots, "matches", func(match scrape.Match) int { return match.ID })
+	if err != nil {
+		return err
+	}
+	rankings := map[string][]scrape.Ranking{}
+	names, err := hub.regions.Regions()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		file, err := hub.regions.Read(name)
+		if err != nil {
+			return err
+		}
+		var regionRankings []scrape.Ranking
+		if err := json.Unmarshal(file, &regionRankings); err != nil {
+			return err
+		}
+		rankings[name] = regionRankings
+	}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if hub.loaded {
+		now := time.Now().UTC()
+		var events []ChangeEvent
+
+		for _, id := range sortedKeys(threads) {
+			previous, existed := hub.threads[id]
+			switch {
+			case !existed:
+				events = append(events,
+					ChangeEvent{Topic: "threads:new", Type: ChangeCreated, Time: now, Data: displayThread(threads[id])},
+					ChangeEvent{Topic: fmt.Sprintf("thread:%d", id), Type: ChangeCreated, Time: now, Data: displayThread(threads[id])})
+			case !reflect.DeepEqual(threadContent(previous), threadContent(threads[id])):
+				events = append(events, ChangeEvent{Topic: fmt.Sprintf("thread:%d", id), Type: ChangeUpdated, Time: now, Data: displayThread(threads[id]), Previous: displayThread(previous)})
+			}
+		}
+		for _, id := range sortedKeys(hub.threads) {
+			if _, stillListed := threads[id]; !stillListed {
+				events = append(events, ChangeEvent{Topic: fmt.Sprintf("thread:%d", id), Type: ChangeRemoved, Time: now, Data: displayThread(hub.threads[id])})
+			}
+		}
+
+		for _, id := range sortedKeys(matches) {
+			previous, existed := hub.matches[id]
+			switch {
+			case !existed:
+				events = append(events,
+					ChangeEvent{Topic: "matches:new", Type: ChangeCreated, Time: now, Data: displayMatch(matches[id])},
+					ChangeEvent{Topic: fmt.Sprintf("match:%d", id), Type: ChangeCreated, Time: now, Data: displayMatch(matches[id])})
+			case !reflect.DeepEqual(matchContent(previous), matchContent(matches[id])):
+				events = append(events, ChangeEvent{Topic: fmt.Sprintf("match:%d", id), Type: ChangeUpdated, Time: now, Data: displayMatch(matches[id]), Previous: displayMatch(previous)})
+			}
+		}
+		for _, id := range sortedKeys(hub.matches) {
+			if _, stillListed := matches[id]; !stillListed {
+				events = append(events, ChangeEvent{Topic: fmt.Sprintf("match:%d", id), Type: ChangeRemoved, Time: now, Data: displayMatch(hub.matches[id])})
+			}
+		}
+
+		for _, region := range names {
+			previous, existed := hub.rankings[region]
+			switch {
+			case !existed:
+				events = append(events, ChangeEvent{Topic: "rankings:" + region, Type: ChangeCreated, Time: now, Data: rankings[region]})
+			case !reflect.DeepEqual(previous, rankings[region]):
+				events = append(events, ChangeEvent{Topic: "rankings:" + region, Type: ChangeUpdated, Time: now, Data: rankings[region], Previous: previous})
+			}
+		}
+
+		for _, event := range events {
+			hub.publish(event)
+		}
+	}
+
+	hub.loaded = true
+	hub.threads = threads
+	hub.matches = matches
+	hub.rankings = rankings
+	return nil
+}
+
+// Sends event to every subscriber of its topic. Callers must hold hub.mu.
+func (hub *ChangeHub) publish(event ChangeEvent) {
+	for sub := range hub.subscribers {
+		if !sub.topics[event.Topic] {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			hub.remove(sub)
+		}
+	}
+}
+
+// Registers a subscriber with no topics.
+func (hub *ChangeHub) subscribe() *subscriber {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	sub := &subscriber{topics: map[string]bool{}, events: make(chan ChangeEvent, 64)}
+	hub.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Adds or removes topics of a subscriber.
+func (hub *ChangeHub) setTopics(sub *subscriber, topics []string, subscribed bool) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	for _, topic := range topics {
+		if subscribed {
+			sub.topics[topic] = true
+		} else {
+			delete(sub.topics, topic)
+		}
+	}
+}
+
+// Returns the topics of a subscriber in alphabetical order.
+func (hub *ChangeHub) topics(sub *subscriber) []string {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	topics := []string{}
+	for topic := range sub.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Unregisters a subscriber and closes its channel.
+func (hub *ChangeHub) unsubscribe(sub *subscriber) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.remove(sub)
+}
+
+// Callers must hold hub.mu.
+func (hub *ChangeHub) remove(sub *subscriber) {
+	if sub.closed {
+		return
+	}
+	delete(hub.subscribers, sub)
+	close(sub.events)
+	sub.closed = true
+}
+
+// Reads the newest snapshot of a section keyed by ID. A section without snapshots is empty.
+func latestByID[T any](snapshots *SnapshotIndex, section string, itemID func(T) int) (map[int]T, error) {
+	list, err := latestItems[T](snapshots, section)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[int]T, len(list))
+	for _, item := range list {
+		items[itemID(item)] = item
+	}
+	return items, nil
+}
+
+func sortedKeys[T any](items map[int]T) []int {
+	keys := make([]int, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,269 @@
+package restAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/model"
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+// Returned when no snapshot or ranking contains the requested entity.
+var ErrEntityNotFound = errors.New("entity not found")
+
+// Response documents are defined in model so the client package can share them.
+type (
+	MatchDetail = model.MatchDetail
+	Team        = model.Team
+)
+
+// Time zone match dates and times are displayed in, see SetDisplayLocation.
+var displayLocation = time.UTC
+
+// Sets the time zone the REST, GraphQL, gRPC and static APIs display match dates and times in.
+func SetDisplayLocation(location *time.Location) {
+	displayLocation = location
+}
+
+// Granularity of the time threads and matches are displayed at. Countdowns show whole minutes, and within a minute
+// every request renders the same ages and rates, so repeated requests get the same body and ETag.
+const displayInterval = time.Minute
+
+// Clock read by displayTime, replaced in tests.
+var timeNow = time.Now
+
+// Returns the time threads and matches are displayed at: now, truncated to displayInterval.
+func displayTime() time.Time {
+	return timeNow().Truncate(displayInterval)
+}
+
+// Returns a match as displayed right now, with its countdown recomputed. See scrape.Match.At.
+func displayMatch(match scrape.Match) scrape.Match {
+	return match.At(displayTime(), displayLocation)
+}
+
+// Displays every match in place and returns them.
+func displayMatches(matches []scrape.Match) []scrape.Match {
+	for i := range matches {
+		matches[i] = displayMatch(matches[i])
+	}
+	return matches
+}
+
+// Returns a thread as served right now, with its age and engagement rates. See scrape.Thread.At.
+func displayThread(thread scrape.Thread) scrape.Thread {
+	return thread.At(displayTime())
+}
+
+// Displays every thread in place and returns them.
+func displayThreads(threads []scrape.Thread) []scrape.Thread {
+	for i := range threads {
+		threads[i] = displayThread(threads[i])
+	}
+	return threads
+}
+
+// Reports whether displaySnapshot renders a section as of now, so that its responses change between scrapes.
+func displayedNow(section string) bool {
+	return section == "threads" || section == "matches"
+}
+
+// Rewrites a thread or match snapshot file as displayed at now, indented like the scraper writes it. Files of
+// other sections are returned as they are.
+func displaySnapshot(section string, file []byte, now time.Time) ([]byte, error) {
+	switch section {
+	case "threads":
+		return displayFile(file, func(thread scrape.Thread) scrape.Thread { return thread.At(now) })
+	case "matches":
+		return displayFile(file, func(match scrape.Match) scrape.Match { return match.At(now, displayLocation) })
+	}
+	return file, nil
+}
+
+func displayFile[T any](file []byte, display func(T) T) ([]byte, error) {
+	var items []T
+	if err := json.Unmarshal(file, &items); err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i] = display(items[i])
+	}
+	return json.MarshalIndent(items, "", "    ")
+}
+
+// Returns the last path segment of a team URL, e.g. "sentinels" for https://www.vlr.gg/team/2/sentinels.
+func teamSlug(teamURL string) string {
+	return path.Base(strings.TrimSuffix(teamURL, "/"))
+}
+
+// Searches the snapshots of a section, newest first, for the item with the given ID.
+// Older snapshots are searched too so matches that have dropped off the listing can still be linked to. Only the
+// snapshot holding the item is decoded in full; the others are ruled out through the index's IDs.
+func findByID[T any](snapshots *SnapshotIndex, section string, id int, itemID func(T) int) (T, error) {
+	var zero T
+
+	list, err := snapshots.List(section)
+	if err != nil {
+		return zero, err
+	}
+	snapshots.forget(section, list)
+
+	for _, snapshot := range list {
+		found, err := snapshots.contains(snapshot, id)
+		if err != nil {
+			return zero, err
+		}
+		if !found {
+			continue
+		}
+
+		file, err := snapshot.Read()
+		if err != nil {
+			return zero, err
+		}
+
+		var items []T
+		if err := json.Unmarshal(file, &items); err != nil {
+			return zero, err
+		}
+		for _, item := range items {
+			if itemID(item) == id {
+				return item, nil
+			}
+		}
+	}
+
+	return zero, ErrEntityNotFound
+}
+
+// Reads the items of the newest snapshot of a section. A section without snapshots is empty.
+func latestItems[T any](snapshots *SnapshotIndex, section string) ([]T, error) {
+	snapshot, err := snapshots.Latest(section)
+	if errors.Is(err, ErrSnapshotNotFound) {
+		return []T{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := snapshot.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []T
+	if err := json.Unmarshal(file, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Returns the rankings of every scraped region.
+func allRankings(regions *RegionIndex) ([]scrape.Ranking, error) {
+	names, err := regions.Regions()
+	if err != nil {
+		return nil, err
+	}
+
+	var rankings []scrape.Ranking
+	for _, name := range names {
+		file, err := regions.Read(name)
+		if err != nil {
+			return nil, err
+		}
+
+		var regionRankings []scrape.Ranking
+		if err := json.Unmarshal(file, &regionRankings); err != nil {
+			return nil, err
+		}
+		rankings = append(rankings, regionRankings...)
+	}
+
+	return rankings, nil
+}
+
+// Looks up a thread by ID.
+func findThread(snapshots *SnapshotIndex, id int) (scrape.Thread, error) {
+	thread, err := findByID(snapshots, "threads", id, func(thread scrape.Thread) int { return thread.ID })
+	return displayThread(thread), err
+}
+
+// Looks up a match by ID and attaches each team's ranking.
+func findMatch(snapshots *SnapshotIndex, regions *RegionIndex, id int) (MatchDetail, error) {
+	match, err := findByID(snapshots, "matches", id, func(match scrape.Match) int { return match.ID })
+	if err != nil {
+		return MatchDetail{}, err
+	}
+
+	rankings, err := allRankings(regions)
+	if err != nil {
+		return MatchDetail{}, err
+	}
+
+	return matchDetail(displayMatch(match), rankings), nil
+}
+
+// Attaches the first ranking of each team of a match.
+func matchDetail(match scrape.Match, rankings []scrape.Ranking) MatchDetail {
+	detail := MatchDetail{Match: match}
+	for i := range rankings {
+		if strings.EqualFold(rankings[i].TeamName, match.Team1) && detail.Team1Ranking == nil {
+			detail.Team1Ranking = &rankings[i]
+		}
+		if strings.EqualFold(rankings[i].TeamName, match.Team2) && detail.Team2Ranking == nil {
+			detail.Team2Ranking = &rankings[i]
+		}
+	}
+
+	return detail
+}
+
+// Looks up a team by the slug of its vlr.gg URL, gathering its rankings and its matches in the newest snapshot.
+func findTeam(snapshots *SnapshotIndex, regions *RegionIndex, slug string) (Team, error) {
+	rankings, err := allRankings(regions)
+	if err != nil {
+		return Team{}, err
+	}
+
+	matches, err := latestItems[scrape.Match](snapshots, "matches")
+	if err != nil {
+		return Team{}, err
+	}
+
+	team := assembleTeam("", rankings, displayMatches(matches), func(ranking scrape.Ranking) bool {
+		return strings.EqualFold(teamSlug(ranking.TeamURL), slug)
+	})
+	if len(team.Rankings) == 0 {
+		return Team{}, ErrEntityNotFound
+	}
+
+	return team, nil
+}
+
+// Assembles a team from the rankings ranked accepts and the matches it plays in.
+// name is kept for teams without any accepted ranking, which then have no slug or URL.
+func assembleTeam(name string, rankings []scrape.Ranking, matches []scrape.Match, ranked func(scrape.Ranking) bool) Team {
+	team := Team{Name: name, Rankings: []scrape.Ranking{}, Matches: []scrape.Match{}}
+	for _, ranking := range rankings {
+		if !ranked(ranking) {
+			continue
+		}
+		if len(team.Rankings) == 0 {
+			team.Slug = teamSlug(ranking.TeamURL)
+			team.Name = ranking.TeamName
+			team.TeamURL = ranking.TeamURL
+		}
+		team.Rankings = append(team.Rankings, ranking)
+	}
+
+	for _, match := range matches {
+		if team.Name != "" && (strings.EqualFold(match.Team1, team.Name) || strings.EqualFold(match.Team2, team.Name)) {
+			team.Matches = append(team.Matches, match)
+		}
+	}
+
+	return team
+}
@@ -0,0 +1,45 @@
+package restAPI
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/mrovengerdev/vlrscrape/logging"
+	"github.com/mrovengerdev/vlrscrape/model"
+)
+
+// Logs failed requests, live polls and the servers' lifecycle.
+var logger = slog.Default()
+
+// Replaces the logger used by the REST and gRPC APIs and the live feed.
+func SetLogger(l *slog.Logger) {
+	logger = l
+}
+
+// JSON body returned for every failed request.
+type APIError = model.APIError
+
+// Writes a JSON error body with the given status code.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, APIError{
+		Status:  status,
+		Error:   http.StatusText(status),
+		Message: message,
+	})
+}
+
+// Logs the underlying error and responds with a 500 that doesn't expose file paths or internals to the client.
+func writeInternalError(w http.ResponseWriter, err error) {
+	logger.Error("request failed", logging.Err(err))
+	writeError(w, http.StatusInternalServerError, "internal server error")
+}
+
+// Encodes value as the JSON response body with the given status code.
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logger.Warn("writing response failed", logging.Err(err))
+	}
+}
@@ -0,0 +1,67 @@
+package restAPI
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+)
+
+func TestErrorResponses(t *testing.T) {
+	handler, _ := newTestHandler(t)
+
+	// A threads snapshot that can't be decoded, to check that internal errors aren't passed on.
+	brokenDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(brokenDir, "outputThreads_2024-11-06_15-04-05.json"), []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	broken, _ := NewHandler(brokenDir, filepath.Join(brokenDir, "ranking"), map[string]string{"threads": "outputThreads"}, NewLiveFeed(), nil, config.Default().Server.WebSocket)
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		path    string
+		status  int
+		message string // Part of the expected message.
+	}{
+		{"region with a path", handler, "/Ranking/..%2Franking", http.StatusBadRequest, `invalid region "../ranking"`},
+		{"region with a dot", handler, "/Ranking/Europe.json", http.StatusBadRequest, "invalid region"},
+		{"unknown region", handler, "/Ranking/Mars", http.StatusNotFound, `no rankings for region "Mars"`},
+		{"unknown data object", handler, "/rankings", http.StatusNotFound, "available: matches, threads"},
+		{"invalid data object", handler, "/threads%20all", http.StatusBadRequest, "invalid data object"},
+		{"malformed timestamp", handler, "/threads/at/yesterday", http.StatusBadRequest, "must use the layout"},
+		{"missing snapshot", handler, "/matches/at/2020-01-01_00-00-00", http.StatusNotFound, "no matches snapshot found"},
+		{"non-numeric id", handler, "/matches/abc", http.StatusBadRequest, `match id "abc" must be an integer`},
+		{"missing thread", handler, "/threads/999", http.StatusNotFound, "thread 999 not found"},
+		{"no endpoint", handler, "/threads/10/comments", http.StatusNotFound, "no endpoint at /threads/10/comments"},
+		{"unreadable snapshot", broken, "/threads", http.StatusInternalServerError, "internal server error"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			test.handler.ServeHTTP(response, httptest.NewRequest("GET", test.path, nil))
+
+			if response.Code != test.status {
+				t.Fatalf("status = %d, want %d; body: %s", response.Code, test.status, response.Body)
+			}
+			if contentType := response.Header().Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", contentType)
+			}
+			var body APIError
+			if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body %s isn't an APIError: %v", response.Body, err)
+			}
+			if body.Status != test.status || body.Error != http.StatusText(test.status) || !strings.Contains(body.Message, test.message) {
+				t.Errorf("got %+v, want status %d with a message containing %q", body, test.status, test.message)
+			}
+			if strings.Contains(body.Message, "invalid character") || strings.Contains(body.Message, brokenDir) {
+				t.Errorf("message %q exposes internals", body.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,593 @@
+package restAPI
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/mrovengerdev/vlrscrape/logging"
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+// Limits checked before a GraphQL query runs. Depth counts nested fields. Complexity counts every field,
+// multiplying the cost of a list field's selection by the number of items its first argument allows.
+// Introspection fields (those starting with __) count towards neither.
+const (
+	graphQLMaxDepth      = 6
+	graphQLMaxComplexity = 10000
+	graphQLMaxBodySize   = 1 << 20
+)
+
+// Body of a GraphQL request.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Body of a GraphQL response. Queries rejected before execution have errors but no data.
+type GraphQLResponse struct {
+	Data   any                        `json:"data,omitempty"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+// The scraped data a single GraphQL request resolves against. Each source is read at most once per
+// request, however many fields need it, so every field of a response sees the same scrape.
+type graphQLData struct {
+	snapshots *SnapshotIndex
+	regions   *RegionIndex
+	threads   func() ([]scrape.Thread, error)
+	matches   func() ([]scrape.Match, error)
+	rankings  func() ([]scrape.Ranking, error)
+}
+
+type graphQLDataKey struct{}
+
+func newGraphQLData(snapshots *SnapshotIndex, regions *RegionIndex) *graphQLData {
+	return &graphQLData{
+		snapshots: snapshots,
+		regions:   regions,
+		threads: sync.OnceValues(func() ([]scrape.Thread, error) {
+			threads, err := latestItems[scrape.Thread](snapshots, "threads")
+			return displayThreads(threads), err
+		}),
+		matches: sync.OnceValues(func() ([]scrape.Match, error) {
+			matches, err := latestItems[scrape.Match](snapshots, "matches")
+			return displayMatches(matches), err
+		}),
+		rankings: sync.OnceValues(func() ([]scrape.Ranking, error) { return allRankings(regions) }),
+	}
+}
+
+// Returns the data of the request a resolver runs in.
+func dataOf(p graphql.ResolveParams) *graphQLData {
+	return p.Context.Value(graphQLDataKey{}).(*graphQLData)
+}
+
+// Assembles a team from the rankings ranked accepts, keeping name for teams that aren't ranked anywhere.
+func (data *graphQLData) team(name string, ranked func(scrape.Ranking) bool) (Team, error) {
+	rankings, err := data.rankings()
+	if err != nil {
+		return Team{}, err
+	}
+	matches, err := data.matches()
+	if err != nil {
+		return Team{}, err
+	}
+	return assembleTeam(name, rankings, matches, ranked), nil
+}
+
+// Looks up a team by name, as written in matches and rankings.
+func (data *graphQLData) teamNamed(name string) (Team, error) {
+	return data.team(name, func(ranking scrape.Ranking) bool { return strings.EqualFold(ranking.TeamName, name) })
+}
+
+// Builds the schema. Object fields are named after the JSON fields of the REST API, and relationships
+// (match → teams → ranking, ranking → team → matches) are resolved by team name.
+func newGraphQLSchema() (graphql.Schema, error) {
+	threadType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Thread",
+		Description: "A forum thread.",
+		Fields: graphql.Fields{
+			"id":                 &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"title":              &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"thread_url":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"frag_count":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"date_published":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"date_published_ago": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"comment_count":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"published_at":       &graphql.Field{Type: graphql.DateTime, Description: "Posting time in UTC, null when vlr.gg's dates can't be read."},
+			"age_hours":          &graphql.Field{Type: graphql.Float, Description: "Hours since published_at."},
+			"frags_per_hour":     &graphql.Field{Type: graphql.Float, Description: "Frags per hour since published_at, counting at least one hour."},
+			"comments_per_hour":  &graphql.Field{Type: graphql.Float, Description: "Comments per hour since published_at, counting at least one hour."},
+		},
+	})
+
+	// Matches, rankings and teams refer to each other, so their fields are declared once all three exist.
+	var matchType, rankingType, teamType *graphql.Object
+
+	matchType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Match",
+		Description: "An upcoming, live or recently finished match.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":               &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"match_url":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"tournament":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"team1":            &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"team2":            &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"score1":           &graphql.Field{Type: graphql.String},
+				"score2":           &graphql.Field{Type: graphql.String},
+				"date":             &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"match_time":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"time_until_match": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"scheduled_at":     &graphql.Field{Type: graphql.DateTime, Description: "Start time in UTC, null when vlr.gg doesn't give one."},
+				"teams": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(teamType))),
+					Description: "Both teams, in the order of team1 and team2.",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						match := p.Source.(scrape.Match)
+						var teams []Team
+						for _, name := range []string{match.Team1, match.Team2} {
+							team, err := dataOf(p).teamNamed(name)
+							if err != nil {
+								return nil, resolverError(err)
+							}
+							teams = append(teams, team)
+						}
+						return teams, nil
+					},
+				},
+			}
+		}),
+	})
+
+	rankingType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Ranking",
+		Description: "The rank of a team in a region.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"rank":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"region":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"team_name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"elo":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"team_url":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"team": &graphql.Field{
+					Type: graphql.NewNonNull(teamType),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return dataOf(p).teamNamed(p.Source.(scrape.Ranking).TeamName)
+					},
+				},
+			}
+		}),
+	})
+
+	teamType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Team",
+		Description: "A team assembled from its regional rankings and the matches it plays in. Unranked teams have an empty slug and URL.",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"slug":     &graphql.Field{Type: graphql.String},
+				"name":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"team_url": &graphql.Field{Type: graphql.String},
+				"ranking": &graphql.Field{
+					Type:        rankingType,
+					Description: "The current ranking of the team, in region when given.",
+					Args: graphql.FieldConfigArgument{
+						"region": &graphql.ArgumentConfig{Type: graphql.String},
+					},
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						region, _ := p.Args["region"].(string)
+						for _, ranking := range p.Source.(Team).Rankings {
+							if region == "" || strings.EqualFold(ranking.Region, region) {
+								return ranking, nil
+							}
+						}
+						return nil, nil
+					},
+				},
+				"rankings": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(rankingType)))},
+				"matches": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(matchType))),
+					Args: listArgs(matchFilters),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return applyListArgs(p.Source.(Team).Matches, p.Args, matchFilters)
+					},
+				},
+			}
+		}),
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"threads": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(threadType))),
+				Description: "Threads of the newest snapshot.",
+				Args:        listArgs(threadFilters),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					threads, err := dataOf(p).threads()
+					if err != nil {
+						return nil, resolverError(err)
+					}
+					return applyListArgs(threads, p.Args, threadFilters)
+				},
+			},
+			"thread": &graphql.Field{
+				Type:        threadType,
+				Description: "A single thread by ID, searched for in every snapshot.",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					thread, err := findThread(dataOf(p).snapshots, p.Args["id"].(int))
+					return entityOrNil(thread, err)
+				},
+			},
+			"matches": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(matchType))),
+				Description: "Matches of the newest snapshot.",
+				Args:        listArgs(matchFilters),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					matches, err := dataOf(p).matches()
+					if err != nil {
+						return nil, resolverError(err)
+					}
+					return applyListArgs(matches, p.Args, matchFilters)
+				},
+			},
+			"match": &graphql.Field{
+				Type:        matchType,
+				Description: "A single match by ID, searched for in every snapshot.",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					match, err := findByID(dataOf(p).snapshots, "matches", p.Args["id"].(int), func(match scrape.Match) int { return match.ID })
+					return entityOrNil(displayMatch(match), err)
+				},
+			},
+			"rankings": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(rankingType))),
+				Description: "Rankings of every region, or only of region when given.",
+				Args: withArgs(listArgs(rankingFilters), graphql.FieldConfigArgument{
+					"region": &graphql.ArgumentConfig{Type: graphql.String, Description: "Region as written on vlr.gg, e.g. Asia-Pacific."},
+				}),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					rankings, err := dataOf(p).rankings()
+					if err != nil {
+						return nil, resolverError(err)
+					}
+					if region, ok := p.Args["region"].(string); ok {
+						var inRegion []scrape.Ranking
+						for _, ranking := range rankings {
+							if strings.EqualFold(ranking.Region, region) {
+								inRegion = append(inRegion, ranking)
+							}
+						}
+						rankings = inRegion
+					}
+					return applyListArgs(rankings, p.Args, rankingFilters)
+				},
+			},
+			"regions": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))),
+				Description: "Every region with rankings.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					regions, err := dataOf(p).regions.Regions()
+					return regions, resolverError(err)
+				},
+			},
+			"team": &graphql.Field{
+				Type:        teamType,
+				Description: "A ranked team by the slug of its vlr.gg URL, e.g. sentinels.",
+				Args: graphql.FieldConfigArgument{
+					"slug": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					slug := p.Args["slug"].(string)
+					team, err := dataOf(p).team("", func(ranking scrape.Ranking) bool {
+						return strings.EqualFold(teamSlug(ranking.TeamURL), slug)
+					})
+					if err != nil || len(team.Rankings) == 0 {
+						return nil, resolverError(err)
+					}
+					return team, nil
+				},
+			},
+			"teams": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(teamType))),
+				Description: "Every ranked team, ordered by name.",
+				Args:        listArgs(map[string]filter[Team]{}),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					rankings, err := dataOf(p).rankings()
+					if err != nil {
+						return nil, resolverError(err)
+					}
+
+					var names []string
+					seen := map[string]bool{}
+					for _, ranking := range rankings {
+						if key := strings.ToLower(ranking.TeamName); !seen[key] {
+							seen[key] = true
+							names = append(names, ranking.TeamName)
+						}
+					}
+					sort.Strings(names)
+
+					names, err = applyListArgs(names, p.Args, map[string]filter[string]{})
+					if err != nil {
+						return nil, resolverError(err)
+					}
+					teams := make([]Team, 0, len(names))
+					for _, name := range names {
+						team, err := dataOf(p).teamNamed(name)
+						if err != nil {
+							return nil, resolverError(err)
+						}
+						teams = append(teams, team)
+					}
+					return teams, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// Arguments of a list field: the filters of its item type along with first and offset.
+// Filters named min_ or max_ take integers, every other filter a string.
+func listArgs[T any](filters map[string]filter[T]) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{
+		"first":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLimit, Description: fmt.Sprintf("Number of items between 1 and %d.", maxLimit)},
+		"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0, Description: "Number of items to skip."},
+	}
+	for _, param := range filterParams(filters) {
+		argType := graphql.String
+		if param.Schema.Type == "integer" {
+			argType = graphql.Int
+		}
+		args[param.Name] = &graphql.ArgumentConfig{Type: argType, Description: param.Description}
+	}
+	return args
+}
+
+// Returns args with the arguments of extra added.
+func withArgs(args graphql.FieldConfigArgument, extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	for name, arg := range extra {
+		args[name] = arg
+	}
+	return args
+}
+
+// Applies the filter, first and offset arguments of a list field to items.
+func applyListArgs[T any](items []T, args map[string]any, filters map[string]filter[T]) ([]T, error) {
+	first, _ := args["first"].(int)
+	offset, _ := args["offset"].(int)
+	if first < 1 || first > maxLimit {
+		return nil, newQueryError("first must be between 1 and %d", maxLimit)
+	}
+	if offset < 0 {
+		return nil, newQueryError("offset must not be negative")
+	}
+
+	matched := []T{}
+	for _, item := range items {
+		keep := true
+		for name, apply := range filters {
+			value, ok := args[name]
+			if !ok {
+				continue
+			}
+			passed, err := apply(item, fmt.Sprint(value))
+			if err != nil {
+				return nil, resolverError(err)
+			}
+			keep = keep && passed
+		}
+		if keep {
+			matched = append(matched, item)
+		}
+	}
+
+	if offset >= len(matched) {
+		return []T{}, nil
+	}
+	return matched[offset:min(offset+first, len(matched))], nil
+}
+
+// Turns a missing entity into a null field rather than an error.
+func entityOrNil[T any](entity T, err error) (any, error) {
+	if errors.Is(err, ErrEntityNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, resolverError(err)
+	}
+	return entity, nil
+}
+
+// Passes on bad arguments and missing entities, which the client can act on, and replaces any other resolver error
+// with "internal error", logging the original like writeInternalError does.
+func resolverError(err error) error {
+	var badQuery *queryError
+	if err == nil || errors.As(err, &badQuery) || errors.Is(err, ErrEntityNotFound) {
+		return err
+	}
+	logger.Error("GraphQL resolver failed", logging.Err(err))
+	return errors.New("internal error")
+}
+
+// Serves GraphQL queries sent as JSON in the body of a POST request. Rejected requests get a 400 with a
+// GraphQLResponse carrying the errors, like queries that fail to parse or validate.
+func serveGraphQL(schema graphql.Schema, snapshots *SnapshotIndex, regions *RegionIndex) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request GraphQLRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, graphQLMaxBodySize)).Decode(&request); err != nil {
+			writeJSON(w, http.StatusBadRequest, GraphQLResponse{Errors: gqlerrors.FormatErrors(fmt.Errorf("invalid GraphQL request: %w", err))})
+			return
+		}
+		if strings.TrimSpace(request.Query) == "" {
+			writeJSON(w, http.StatusBadRequest, GraphQLResponse{Errors: gqlerrors.FormatErrors(errors.New("GraphQL request has no query"))})
+			return
+		}
+
+		document, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+			Body: []byte(request.Query),
+			Name: "GraphQL request",
+		})})
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, GraphQLResponse{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+		if validation := graphql.ValidateDocument(&schema, document, nil); !validation.IsValid {
+			writeJSON(w, http.StatusBadRequest, GraphQLResponse{Errors: validation.Errors})
+			return
+		}
+		if err := checkGraphQLLimits(&schema, document, request.Variables); err != nil {
+			writeJSON(w, http.StatusBadRequest, GraphQLResponse{Errors: gqlerrors.FormatErrors(err)})
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), graphQLDataKey{}, newGraphQLData(snapshots, regions))
+		result := graphql.Execute(graphql.ExecuteParams{
+			Schema:        schema,
+			AST:           document,
+			OperationName: request.OperationName,
+			Args:          request.Variables,
+			Context:       ctx,
+		})
+		writeJSON(w, http.StatusOK, GraphQLResponse{Data: result.Data, Errors: result.Errors})
+	}
+}
+
+// Rejects documents with an operation nested deeper than graphQLMaxDepth or costing more than
+// graphQLMaxComplexity. Runs after validation, so every field and fragment is known and free of cycles.
+func checkGraphQLLimits(schema *graphql.Schema, document *ast.Document, variables map[string]any) error {
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+	cost := &graphQLCost{schema: schema, fragments: fragments, variables: variables}
+
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		name := "query"
+		if operation.Name != nil {
+			name = "query " + operation.Name.Value
+		}
+
+		// Variables the request leaves out take the default of their definition in the operation.
+		cost.defaults = map[string]ast.Value{}
+		for _, definition := range operation.VariableDefinitions {
+			if definition.DefaultValue != nil {
+				cost.defaults[definition.Variable.Name.Value] = definition.DefaultValue
+			}
+		}
+
+		depth, complexity := cost.selectionSet(schema.QueryType(), operation.SelectionSet)
+		if depth > graphQLMaxDepth {
+			return fmt.Errorf("%s is nested %d levels deep, the maximum is %d", name, depth, graphQLMaxDepth)
+		}
+		if complexity > graphQLMaxComplexity {
+			return fmt.Errorf("%s has a complexity of %d, the maximum is %d", name, complexity, graphQLMaxComplexity)
+		}
+	}
+	return nil
+}
+
+// Computes the depth and complexity of selections within a document.
+type graphQLCost struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+	defaults  map[string]ast.Value // Default values of the variables of the operation being measured.
+}
+
+// Returns the depth and complexity of a selection set on object, expanding fragments.
+// Selections on scalars have neither.
+func (cost *graphQLCost) selectionSet(object *graphql.Object, selectionSet *ast.SelectionSet) (depth int, complexity int) {
+	if selectionSet == nil || object == nil {
+		return 0, 0
+	}
+
+	for _, selection := range selectionSet.Selections {
+		var selectionDepth, selectionComplexity int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			field, ok := object.Fields()[selection.Name.Value]
+			if !ok {
+				continue // Introspection fields, which aren't limited.
+			}
+			child, _ := graphql.GetNamed(field.Type).(*graphql.Object)
+			childDepth, childComplexity := cost.selectionSet(child, selection.SelectionSet)
+			selectionDepth = childDepth + 1
+			selectionComplexity = 1 + cost.listSize(field, selection)*childComplexity
+		case *ast.InlineFragment:
+			selectionDepth, selectionComplexity = cost.selectionSet(object, selection.SelectionSet)
+		case *ast.FragmentSpread:
+			if fragment, ok := cost.fragments[selection.Name.Value]; ok {
+				selectionDepth, selectionComplexity = cost.selectionSet(object, fragment.SelectionSet)
+			}
+		}
+
+		depth = max(depth, selectionDepth)
+		complexity += selectionComplexity
+	}
+	return depth, complexity
+}
+
+// Returns the number of items a field may resolve to: the value of its first argument for fields taking one,
+// otherwise 1. Lists without a first argument are small by nature, such as the two teams of a match.
+func (cost *graphQLCost) listSize(field *graphql.FieldDefinition, selection *ast.Field) int {
+	for _, arg := range field.Args {
+		if arg.Name() != "first" {
+			continue
+		}
+
+		size := defaultLimit
+		for _, argument := range selection.Arguments {
+			if argument.Name.Value != "first" {
+				continue
+			}
+			var value string
+			switch argumentValue := argument.Value.(type) {
+			case *ast.IntValue:
+				value = argumentValue.Value
+			case *ast.Variable:
+				if variable, ok := cost.variables[argumentValue.Name.Value]; ok {
+					value = fmt.Sprint(variable)
+				} else if defaultValue, ok := cost.defaults[argumentValue.Name.Value].(*ast.IntValue); ok {
+					value = defaultValue.Value
+				}
+			}
+			if value != "" {
+				// Values out of range count as the largest page; execution rejects them anyway.
+				parsed, err := strconv.Atoi(value)
+				if err != nil || parsed < 1 || parsed > maxLimit {
+					parsed = maxLimit
+				}
+				size = parsed
+			}
+		}
+		return size
+	}
+	return 1
+}
@@ -0,0 +1,78 @@
+package restAPI
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+)
+
+// Nested lists multiply: with first set to n, the query below costs 1 + n*(2 + n).
+func TestGraphQLComplexityUsesVariableDefaults(t *testing.T) {
+	handler, _ := newTestHandler(t)
+	const nested = `matches(first: $n) { teams { matches(first: $n) { id } } }`
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"literal small", `{"query": "{ matches(first: 2) { teams { matches(first: 2) { id } } } }"}`, http.StatusOK},
+		{"literal large", `{"query": "{ matches(first: 500) { teams { matches(first: 500) { id } } } }"}`, http.StatusBadRequest},
+		{"large default", `{"query": "query($n: Int = 500) { ` + nested + ` }"}`, http.StatusBadRequest},
+		{"small variable overrides large default", `{"query": "query($n: Int = 500) { ` + nested + ` }", "variables": {"n": 2}}`, http.StatusOK},
+		{"large variable", `{"query": "query($n: Int) { ` + nested + ` }", "variables": {"n": 500}}`, http.StatusBadRequest},
+		{"no default or variable", `{"query": "query($n: Int) { ` + nested + ` }"}`, http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, httptest.NewRequest("POST", "/graphql", strings.NewReader(test.body)))
+			if response.Code != test.status {
+				t.Errorf("status = %d, want %d; body: %s", response.Code, test.status, response.Body)
+			}
+		})
+	}
+}
+
+func TestGraphQLMissingEntityIsNull(t *testing.T) {
+	handler, _ := newTestHandler(t)
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ thread(id: 999) { id } }"}`)))
+	if response.Code != http.StatusOK || strings.TrimSpace(response.Body.String()) != `{"data":{"thread":null}}` {
+		t.Errorf("got %d %s, want a null thread", response.Code, response.Body)
+	}
+}
+
+func TestGraphQLHidesInternalErrors(t *testing.T) {
+	outputDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outputDir, "outputThreads_2024-11-06_15-04-05.json"), []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sections := map[string]string{"threads": "outputThreads", "matches": "outputMatches"}
+	handler, _ := NewHandler(outputDir, filepath.Join("testdata", "output", "ranking"), sections, NewLiveFeed(), nil, config.Default().Server.WebSocket)
+
+	tests := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{"unreadable snapshot", `{ threads { id } }`, `"message":"internal error"`},
+		{"bad argument", `{ rankings(first: 0) { rank } }`, `"message":"first must be between 1 and`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "`+test.query+`"}`)))
+			body := response.Body.String()
+			if !strings.Contains(body, test.message) || strings.Contains(body, "invalid character") {
+				t.Errorf("got %s, want an error with %s", body, test.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package restAPI
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/logging"
+	"github.com/mrovengerdev/vlrscrape/scrape"
+	"github.com/mrovengerdev/vlrscrape/vlrscrapepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Event types of the live feed as sent by WatchMatches.
+var matchEventTypes = map[string]vlrscrapepb.MatchEvent_Type{
+	EventSnapshot: vlrscrapepb.MatchEvent_TYPE_SNAPSHOT,
+	EventLive:     vlrscrapepb.MatchEvent_TYPE_LIVE,
+	EventScore:    vlrscrapepb.MatchEvent_TYPE_SCORE,
+	EventFinished: vlrscrapepb.MatchEvent_TYPE_FINISHED,
+}
+
+// Implements the VLRScrape gRPC service over the same snapshots and rankings as the REST API.
+type grpcServer struct {
+	vlrscrapepb.UnimplementedVLRScrapeServer
+	snapshots *SnapshotIndex
+	regions   *RegionIndex
+	live      *LiveFeed
+}
+
+// Creates and maintains a gRPC server on addr, alongside the REST API, until ctx is done.
+// Calls in flight get up to shutdownTimeout to finish, after which open streams are cut.
+// access is shared with the REST API so a client has one rate limit across both.
+func ServeGRPC(ctx context.Context, addr string, shutdownTimeout time.Duration, access *AccessControl, outputDir string, rankingDir string, sections map[string]string, live *LiveFeed) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := NewGRPCServer(access, outputDir, rankingDir, sections, live)
+	go func() {
+		<-ctx.Done()
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(shutdownTimeout):
+			server.Stop()
+		}
+	}()
+
+	logger.Info("gRPC API now operating", "addr", listener.Addr().String(), "service", "vlrscrape.v1.VLRScrape")
+	return server.Serve(listener)
+}
+
+// Builds a gRPC server for the VLRScrape service, with calls authenticated and rate limited by access and
+// recorded in the metrics. WatchMatches fails with Unavailable when live is nil.
+func NewGRPCServer(access *AccessControl, outputDir string, rankingDir string, sections map[string]string, live *LiveFeed) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryMetrics, access.UnaryInterceptor()),
+		grpc.ChainStreamInterceptor(streamMetrics, access.StreamInterceptor()),
+	)
+	vlrscrapepb.RegisterVLRScrapeServer(server, &grpcServer{
+		snapshots: NewSnapshotIndex(outputDir, sections),
+		regions:   NewRegionIndex(rankingDir),
+		live:      live,
+	})
+	return server
+}
+
+func (server *grpcServer) GetThread(ctx context.Context, request *vlrscrapepb.GetThreadRequest) (*vlrscrapepb.Thread, error) {
+	thread, err := findThread(server.snapshots, int(request.GetId()))
+	if err != nil {
+		return nil, grpcError(err, fmt.Sprintf("thread %d", request.GetId()))
+	}
+	return threadToProto(thread), nil
+}
+
+func (server *grpcServer) GetMatch(ctx context.Context, request *vlrscrapepb.GetMatchRequest) (*vlrscrapepb.MatchDetail, error) {
+	match, err := findMatch(server.snapshots, server.regions, int(request.GetId()))
+	if err != nil {
+		return nil, grpcError(err, fmt.Sprintf("match %d", request.GetId()))
+	}
+	return &vlrscrapepb.MatchDetail{
+		Match:        matchToProto(match.Match),
+		Team1Ranking: rankingToProto(match.Team1Ranking),
+		Team2Ranking: rankingToProto(match.Team2Ranking),
+	}, nil
+}
+
+func (server *grpcServer) GetTeam(ctx context.Context, request *vlrscrapepb.GetTeamRequest) (*vlrscrapepb.Team, error) {
+	slug := request.GetSlug()
+	if !validName(slug) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid team slug %q", slug)
+	}
+
+	team, err := findTeam(server.snapshots, server.regions, slug)
+	if err != nil {
+		return nil, grpcError(err, fmt.Sprintf("team %q", slug))
+	}
+
+	response := &vlrscrapepb.Team{Slug: team.Slug, Name: team.Name, TeamUrl: team.TeamURL}
+	for _, ranking := range team.Rankings {
+		response.Rankings = append(response.Rankings, rankingToProto(&ranking))
+	}
+	for _, match := range team.Matches {
+		response.Matches = append(response.Matches, matchToProto(match))
+	}
+	return response, nil
+}
+
+func (server *grpcServer) ListThreads(ctx context.Context, request *vlrscrapepb.ListRequest) (*vlrscrapepb.ListThreadsResponse, error) {
+	file, timestamp, err := server.snapshotFile("threads", request.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	threads, meta, err := listItems(file, request, threadFilters, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &vlrscrapepb.ListThreadsResponse{Total: int32(meta.Total), NextPageToken: meta.NextCursor}
+	for _, thread := range threads {
+		response.Threads = append(response.Threads, threadToProto(thread))
+	}
+	return response, nil
+}
+
+func (server *grpcServer) ListMatches(ctx context.Context, request *vlrscrapepb.ListRequest) (*vlrscrapepb.ListMatchesResponse, error) {
+	file, timestamp, err := server.snapshotFile("matches", request.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	matches, meta, err := listItems(file, request, matchFilters, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &vlrscrapepb.ListMatchesResponse{Total: int32(meta.Total), NextPageToken: meta.NextCursor}
+	for _, match := range matches {
+		response.Matches = append(response.Matches, matchToProto(match))
+	}
+	return response, nil
+}
+
+func (server *grpcServer) ListRankings(ctx context.Context, request *vlrscrapepb.ListRankingsRequest) (*vlrscrapepb.ListRankingsResponse, error) {
+	region := request.GetRegion()
+	if !validName(region) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid region %q", region)
+	}
+
+	file, err := server.regions.Read(region)
+	if errors.Is(err, ErrRegionNotFound) {
+		return nil, status.Errorf(codes.NotFound, "no rankings for region %q", region)
+	}
+	if err != nil {
+		return nil, grpcError(err, "")
+	}
+	rankings, meta, err := listItems(file, request.GetQuery(), rankingFilters, strconv.FormatInt(server.regions.ModTime(region).UnixNano(), 10))
+	if err != nil {
+		return nil, err
+	}
+
+	response := &vlrscrapepb.ListRankingsResponse{Total: int32(meta.Total), NextPageToken: meta.NextCursor}
+	for _, ranking := range rankings {
+		response.Rankings = append(response.Rankings, rankingToProto(&ranking))
+	}
+	return response, nil
+}
+
+// Streams the live feed the same way as the Server-Sent Events stream: the events after last_event_id when they
+// are still in the history, otherwise a snapshot of the live matches, then every event as it happens.
+func (server *grpcServer) WatchMatches(request *vlrscrapepb.WatchMatchesRequest, stream grpc.ServerStreamingServer[vlrscrapepb.MatchEvent]) error {
+	if server.live == nil {
+		return status.Error(codes.Unavailable, "live match polling is disabled")
+	}
+	if request.GetLastEventId() < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid last event ID %d", request.GetLastEventId())
+	}
+
+	backlog, events, cancel := server.live.Subscribe(request.GetLastEventId())
+	defer cancel()
+
+	for _, event := range backlog {
+		if err := stream.Send(liveEventToProto(event)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, open := <-events:
+			// A closed channel means this client fell behind; it can resume from the last event it received.
+			if !open {
+				return status.Error(codes.ResourceExhausted, "too slow to keep up, resume with last_event_id")
+			}
+			if err := stream.Send(liveEventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Reads the newest snapshot of a section, or the one pageToken was issued for, as displayed right now, along with
+// its timestamp.
+func (server *grpcServer) snapshotFile(section string, pageToken string) ([]byte, string, error) {
+	snapshot, err := latestOrCursor(server.snapshots, section, url.Values{"cursor": {pageToken}})
+	if err != nil {
+		return nil, "", grpcError(err, "")
+	}
+	file, err := snapshot.Read()
+	if err == nil {
+		file, err = displaySnapshot(section, file, displayTime())
+	}
+	if err != nil {
+		return nil, "", grpcError(err, "")
+	}
+	return file, snapshot.Timestamp, nil
+}
+
+// Runs a list request through the same filtering, sorting and pagination as the REST query parameters. Unlike
+// query parameters, unknown filters are rejected, since they can only be mistakes here.
+func listItems[T any](file []byte, request *vlrscrapepb.ListRequest, filters map[string]filter[T], version string) ([]T, PageMeta, error) {
+	values := url.Values{}
+	for name, value := range request.GetFilters() {
+		if _, ok := filters[name]; !ok {
+			return nil, PageMeta{}, status.Errorf(codes.InvalidArgument, "unknown filter %q", name)
+		}
+		values.Set(name, value)
+	}
+	if request.GetSort() != "" {
+		values.Set("sort", request.GetSort())
+	}
+	if request.GetLimit() != 0 {
+		values.Set("limit", strconv.Itoa(int(request.GetLimit())))
+	}
+	if request.GetPageToken() != "" {
+		values.Set("cursor", request.GetPageToken())
+	}
+
+	page, err := runQuery(file, values, filters, version)
+	if err != nil {
+		return nil, PageMeta{}, grpcError(err, "")
+	}
+
+	items := make([]T, 0, len(page.Data))
+	for _, item := range page.Data {
+		items = append(items, item.(T))
+	}
+	return items, page.Meta, nil
+}
+
+// Converts a lookup error to a gRPC status: missing entities become NotFound, bad queries InvalidArgument,
+// and anything else is logged and reported as Internal without exposing file paths or internals.
+func grpcError(err error, name string) error {
+	var queryErr *queryError
+	switch {
+	case errors.Is(err, ErrEntityNotFound) && name != "":
+		return status.Errorf(codes.NotFound, "%s not found", name)
+	case errors.Is(err, ErrEntityNotFound), errors.Is(err, ErrSnapshotNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &queryErr):
+		return status.Error(codes.InvalidArgument, queryErr.Error())
+	default:
+		logger.Error("gRPC call failed", logging.Err(err))
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func threadToProto(thread scrape.Thread) *vlrscrapepb.Thread {
+	return &vlrscrapepb.Thread{
+		Id:               int64(thread.ID),
+		Title:            thread.Title,
+		ThreadUrl:        thread.ThreadURL,
+		FragCount:        int32(thread.FragCount),
+		DatePublished:    thread.DatePublished,
+		DatePublishedAgo: thread.DatePublishedAgo,
+		CommentCount:     int32(thread.CommentCount),
+		PublishedAt:      timestampOrNil(thread.PublishedAt),
+		AgeHours:         thread.AgeHours,
+		FragsPerHour:     thread.FragsPerHour,
+		CommentsPerHour:  thread.CommentsPerHour,
+	}
+}
+
+func matchToProto(match scrape.Match) *vlrscrapepb.Match {
+	return &vlrscrapepb.Match{
+		Id:             int64(match.ID),
+		MatchUrl:       match.MatchURL,
+		Tournament:     match.Tournament,
+		Team1:          match.Team1,
+		Team2:          match.Team2,
+		Score1:         match.Score1,
+		Score2:         match.Score2,
+		Date:           match.Date,
+		MatchTime:      match.MatchTime,
+		TimeUntilMatch: match.TimeUntilMatch,
+		ScheduledAt:    timestampOrNil(match.ScheduledAt),
+	}
+}
+
+// Returns nil for unknown times.
+func timestampOrNil(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// Returns nil for teams without a ranking.
+func rankingToProto(ranking *scrape.Ranking) *vlrscrapepb.Ranking {
+	if ranking == nil {
+		return nil
+	}
+	return &vlrscrapepb.Ranking{
+		Rank:     int32(ranking.Rank),
+		Region:   ranking.Region,
+		TeamName: ranking.TeamName,
+		Elo:      int32(ranking.ELO),
+		TeamUrl:  ranking.TeamURL,
+	}
+}
+
+func liveEventToProto(event LiveEvent) *vlrscrapepb.MatchEvent {
+	response := &vlrscrapepb.MatchEvent{
+		Id:   event.ID,
+		Type: matchEventTypes[event.Type],
+		Time: timestamppb.New(event.Time),
+	}
+	if event.Match != nil {
+		response.Match = matchToProto(*event.Match)
+	}
+	for _, match := range event.Matches {
+		response.Matches = append(response.Matches, matchToProto(match))
+	}
+	return response
+}
@@ -0,0 +1,77 @@
+package restAPI
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/vlrscrapepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Serves the fixture output directory over an in-memory connection with the given access settings.
+func newTestGRPCClient(t *testing.T, settings config.Access) vlrscrapepb.VLRScrapeClient {
+	t.Helper()
+	outputDir := filepath.Join("testdata", "output")
+	server := NewGRPCServer(NewAccessControl(settings), outputDir, filepath.Join(outputDir, "ranking"), map[string]string{"threads": "outputThreads"}, nil)
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return vlrscrapepb.NewVLRScrapeClient(conn)
+}
+
+func TestGRPCAccessControl(t *testing.T) {
+	settings := config.Access{
+		RequireAPIKey: true,
+		APIKeys:       []config.APIKey{{Name: "test", SHA256: config.HashAPIKey("secret")}},
+		KeyLimit:      config.ClientLimit{RequestsPerSecond: 0.001, Burst: 2},
+		IPLimit:       config.ClientLimit{RequestsPerSecond: 1, Burst: 1},
+	}
+	client := newTestGRPCClient(t, settings)
+
+	tests := []struct {
+		name      string
+		metadata  []string
+		code      codes.Code
+		remaining string
+	}{
+		{"no key", nil, codes.Unauthenticated, ""},
+		{"unknown key", []string{"x-api-key", "guess"}, codes.Unauthenticated, ""},
+		{"key", []string{"x-api-key", "secret"}, codes.OK, "1"},
+		{"bearer key", []string{"authorization", "Bearer secret"}, codes.OK, "0"},
+		{"bucket empty", []string{"x-api-key", "secret"}, codes.ResourceExhausted, "0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), test.metadata...)
+			var header metadata.MD
+			_, err := client.GetThread(ctx, &vlrscrapepb.GetThreadRequest{Id: 10}, grpc.Header(&header))
+			if code := status.Code(err); code != test.code {
+				t.Fatalf("code = %s, want %s (%v)", code, test.code, err)
+			}
+			var remaining string
+			if values := header.Get("x-ratelimit-remaining"); len(values) > 0 {
+				remaining = values[0]
+			}
+			if remaining != test.remaining {
+				t.Errorf("x-ratelimit-remaining = %q, want %q", remaining, test.remaining)
+			}
+		})
+	}
+}
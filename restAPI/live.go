@@ -0,0 +1,255 @@
+package restAPI
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/logging"
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+// Types of LiveEvent.
+const (
+	EventSnapshot = "snapshot" // Every match live at the time, sent to clients that can't resume.
+	EventLive     = "live"     // A match has gone live.
+	EventScore    = "score"    // The score of a live match has changed.
+	EventFinished = "finished" // A match is no longer live. Carries its last known state.
+)
+
+// Number of past events kept so reconnecting clients can resume from their Last-Event-ID.
+const liveHistorySize = 256
+
+// Interval between comments sent to keep idle streams from being closed by proxies.
+const liveHeartbeat = 15 * time.Second
+
+type LiveEvent struct {
+	ID      int64          `json:"id"`
+	Type    string         `json:"type"`
+	Time    time.Time      `json:"time"`
+	Match   *scrape.Match  `json:"match,omitempty"`
+	Matches []scrape.Match `json:"matches,omitempty"` // Only set on snapshot events.
+}
+
+// Tracks the set of live matches and fans out changes to stream subscribers.
+type LiveFeed struct {
+	mu          sync.Mutex
+	current     map[int]scrape.Match
+	history     []LiveEvent
+	lastID      int64
+	subscribers map[chan LiveEvent]struct{}
+}
+
+func NewLiveFeed() *LiveFeed {
+	return &LiveFeed{
+		current:     map[int]scrape.Match{},
+		subscribers: map[chan LiveEvent]struct{}{},
+	}
+}
+
+// Calls fetch every interval until ctx is done, publishing the changes between consecutive results.
+// Failed polls are logged and skipped so a network blip doesn't end every live match.
+func (feed *LiveFeed) Poll(ctx context.Context, interval time.Duration, fetch func() ([]scrape.Match, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		live, err := fetch()
+		if err != nil {
+			logger.Warn("live poll failed", logging.Err(err))
+		} else {
+			feed.Update(live)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Replaces the set of live matches, publishing live, score and finished events for the differences.
+func (feed *LiveFeed) Update(live []scrape.Match) {
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+
+	now := time.Now().UTC()
+	next := make(map[int]scrape.Match, len(live))
+	for _, match := range live {
+		next[match.ID] = match
+
+		previous, wasLive := feed.current[match.ID]
+		switch {
+		case !wasLive:
+			feed.publish(EventLive, now, match)
+		case previous.Score1 != match.Score1 || previous.Score2 != match.Score2:
+			feed.publish(EventScore, now, match)
+		}
+	}
+
+	// Finished matches are published in ID order so every subscriber sees the same sequence.
+	var finished []int
+	for id := range feed.current {
+		if _, stillLive := next[id]; !stillLive {
+			finished = append(finished, id)
+		}
+	}
+	sort.Ints(finished)
+	for _, id := range finished {
+		feed.publish(EventFinished, now, feed.current[id])
+	}
+
+	feed.current = next
+}
+
+// Records an event and sends it to every subscriber. Subscribers too slow to keep up are dropped
+// and can reconnect with their Last-Event-ID. Callers must hold feed.mu.
+func (feed *LiveFeed) publish(eventType string, now time.Time, match scrape.Match) {
+	feed.lastID++
+	event := LiveEvent{ID: feed.lastID, Type: eventType, Time: now, Match: &match}
+
+	feed.history = append(feed.history, event)
+	if len(feed.history) > liveHistorySize {
+		feed.history = feed.history[len(feed.history)-liveHistorySize:]
+	}
+
+	for subscriber := range feed.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			delete(feed.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// Returns every match currently live, ordered by ID.
+func (feed *LiveFeed) snapshot() []scrape.Match {
+	matches := make([]scrape.Match, 0, len(feed.current))
+	for _, match := range feed.current {
+		matches = append(matches, match)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+	return matches
+}
+
+// Registers a subscriber and returns the events it should be sent first: those after lastEventID when
+// they are still in the history, otherwise a snapshot of the live matches. Call cancel when done.
+func (feed *LiveFeed) Subscribe(lastEventID int64) (backlog []LiveEvent, events <-chan LiveEvent, cancel func()) {
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+
+	canResume := lastEventID > 0 && lastEventID <= feed.lastID &&
+		(lastEventID == feed.lastID || (len(feed.history) > 0 && feed.history[0].ID <= lastEventID+1))
+	if canResume {
+		for _, event := range feed.history {
+			if event.ID > lastEventID {
+				backlog = append(backlog, event)
+			}
+		}
+	} else {
+		backlog = []LiveEvent{{ID: feed.lastID, Type: EventSnapshot, Time: time.Now().UTC(), Matches: feed.snapshot()}}
+	}
+
+	subscriber := make(chan LiveEvent, 32)
+	feed.subscribers[subscriber] = struct{}{}
+
+	cancel = func() {
+		feed.mu.Lock()
+		defer feed.mu.Unlock()
+		if _, ok := feed.subscribers[subscriber]; ok {
+			delete(feed.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+	return backlog, subscriber, cancel
+}
+
+// Streams live match events as Server-Sent Events. Clients resume with the Last-Event-ID header
+// (sent automatically by EventSource) or the last_event_id query parameter.
+func serveLiveStream(feed *LiveFeed) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, "streaming is not supported")
+			return
+		}
+
+		lastEventID := r.Header.Get("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = r.URL.Query().Get("last_event_id")
+		}
+		var lastID int64
+		if lastEventID != "" {
+			parsed, err := strconv.ParseInt(lastEventID, 10, 64)
+			if err != nil || parsed < 0 {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid last event ID %q", lastEventID))
+				return
+			}
+			lastID = parsed
+		}
+
+		// The stream outlives the server's write timeout, so it is lifted for this response.
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			writeInternalError(w, err)
+			return
+		}
+
+		backlog, events, cancel := feed.Subscribe(lastID)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "retry: 5000\n\n")
+
+		for _, event := range backlog {
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(liveHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event, open := <-events:
+				// A closed channel means this client fell behind; it will reconnect and resume.
+				if !open {
+					return
+				}
+				if err := writeEvent(w, event); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// Writes one event in the text/event-stream format.
+func writeEvent(w http.ResponseWriter, event LiveEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
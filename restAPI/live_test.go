@@ -0,0 +1,152 @@
+package restAPI
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+func TestLiveFeedUpdate(t *testing.T) {
+	feed := NewLiveFeed()
+	backlog, events, cancel := feed.Subscribe(0)
+	defer cancel()
+	if len(backlog) != 1 || backlog[0].Type != EventSnapshot || len(backlog[0].Matches) != 0 {
+		t.Fatalf("backlog = %+v, want an empty snapshot", backlog)
+	}
+
+	feed.Update([]scrape.Match{{ID: 1, Score1: "0", Score2: "0"}, {ID: 2}})
+	feed.Update([]scrape.Match{{ID: 1, Score1: "1", Score2: "0"}, {ID: 2}})
+	feed.Update([]scrape.Match{{ID: 1, Score1: "1", Score2: "0"}})
+	feed.Update([]scrape.Match{})
+
+	want := []struct {
+		eventType string
+		id        int
+	}{
+		{EventLive, 1},
+		{EventLive, 2},
+		{EventScore, 1},
+		{EventFinished, 2},
+		{EventFinished, 1},
+	}
+	for i, expected := range want {
+		select {
+		case event := <-events:
+			if event.ID != int64(i+1) || event.Type != expected.eventType || event.Match.ID != expected.id {
+				t.Errorf("event %d = %d %s for match %d, want %d %s for match %d", i, event.ID, event.Type, event.Match.ID, i+1, expected.eventType, expected.id)
+			}
+		default:
+			t.Fatalf("only %d events, want %d", i, len(want))
+		}
+	}
+	select {
+	case event := <-events:
+		t.Errorf("unexpected %s event", event.Type)
+	default:
+	}
+}
+
+func TestLiveFeedSubscribe(t *testing.T) {
+	feed := NewLiveFeed()
+	feed.Update([]scrape.Match{{ID: 1}})
+	feed.Update([]scrape.Match{{ID: 1}, {ID: 2}})
+	feed.Update([]scrape.Match{{ID: 2}})
+
+	tests := []struct {
+		name        string
+		lastEventID int64
+		ids         []int64 // IDs of the backlog, or of the snapshot standing in for it.
+		snapshot    bool
+	}{
+		{"fresh client", 0, []int64{3}, true},
+		{"resumes", 1, []int64{2, 3}, false},
+		{"up to date", 3, nil, false},
+		{"unknown ID", 99, []int64{3}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backlog, _, cancel := feed.Subscribe(test.lastEventID)
+			defer cancel()
+
+			var ids []int64
+			for _, event := range backlog {
+				ids = append(ids, event.ID)
+				if (event.Type == EventSnapshot) != test.snapshot {
+					t.Errorf("got a %s event, snapshot wanted: %v", event.Type, test.snapshot)
+				}
+			}
+			if !slices.Equal(ids, test.ids) {
+				t.Errorf("backlog IDs = %v, want %v", ids, test.ids)
+			}
+			if test.snapshot && (len(backlog[0].Matches) != 1 || backlog[0].Matches[0].ID != 2) {
+				t.Errorf("snapshot holds %+v, want match 2", backlog[0].Matches)
+			}
+		})
+	}
+}
+
+func TestLiveStream(t *testing.T) {
+	feed := NewLiveFeed()
+	feed.Update([]scrape.Match{{ID: 1}})
+	server := httptest.NewServer(serveLiveStream(feed))
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "?last_event_id=x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid last event ID: status = %d, want 400", response.StatusCode)
+	}
+
+	request, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Last-Event-ID", "1")
+	response, err = http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", contentType)
+	}
+
+	// Resumed from event 1, the first event the client gets is the next one.
+	lines := make(chan string, 64)
+	go func() {
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	feed.Update([]scrape.Match{{ID: 1, Score1: "1"}})
+
+	var received []string
+	timeout := time.After(5 * time.Second)
+	for len(received) < 3 {
+		select {
+		case line, open := <-lines:
+			if !open {
+				t.Fatalf("stream ended after %q", received)
+			}
+			if strings.HasPrefix(line, "id:") || strings.HasPrefix(line, "event:") || strings.HasPrefix(line, "data:") {
+				received = append(received, line)
+			}
+		case <-timeout:
+			t.Fatalf("got %q before timing out", received)
+		}
+	}
+	if received[0] != "id: 2" || received[1] != "event: score" || !strings.Contains(received[2], `"score1":"1"`) {
+		t.Errorf("got %q, want the score event with ID 2", received)
+	}
+}
@@ -0,0 +1,90 @@
+package restAPI
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Remembers the status code a handler wrote. Flush and Hijack are passed through for the live stream and WebSocket.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	if recorder.status == 0 {
+		recorder.status = status
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Write(data []byte) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+	return recorder.ResponseWriter.Write(data)
+}
+
+func (recorder *statusRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (recorder *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := recorder.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	if recorder.status == 0 {
+		recorder.status = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
+
+// Wraps next with a request latency histogram labelled by the route pattern that matched,
+// which the ServeMux sets on the request once it has routed it. Unrouted requests are labelled "unmatched".
+func withMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		metrics.ObserveSince(metrics.RequestDuration, start, route, r.Method, strconv.Itoa(recorder.status))
+	})
+}
+
+// Records the latency and status code of unary gRPC calls, including those refused by access control.
+func unaryMetrics(ctx context.Context, request any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	response, err := handler(ctx, request)
+	metrics.ObserveSince(metrics.GRPCRequestDuration, start, info.FullMethod, status.Code(err).String())
+	return response, err
+}
+
+// Stream counterpart of unaryMetrics, observed once the stream ends.
+func streamMetrics(server any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(server, stream)
+	metrics.ObserveSince(metrics.GRPCRequestDuration, start, info.FullMethod, status.Code(err).String())
+	return err
+}
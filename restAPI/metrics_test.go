@@ -0,0 +1,82 @@
+package restAPI
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mrovengerdev/vlrscrape/metrics"
+)
+
+// Returns how many requests the histogram of the REST API has observed with the given labels.
+func requestCount(t *testing.T, route string, method string, code string) int {
+	t.Helper()
+	response := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(response, httptest.NewRequest("GET", "/metrics", nil))
+
+	series := `vlrscrape_http_request_duration_seconds_count{code="` + code + `",method="` + method + `",route="` + route + `"} `
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), series); ok {
+			count, err := strconv.Atoi(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return count
+		}
+	}
+	return 0
+}
+
+func TestWithMetrics(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /threads/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "999" {
+			writeError(w, http.StatusNotFound, "thread not found")
+			return
+		}
+		w.Write([]byte("{}"))
+	})
+	mux.HandleFunc("GET /stream", func(w http.ResponseWriter, r *http.Request) {
+		// The live stream and WebSocket need the writer's optional interfaces through the recorder.
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("the recorder hides http.Flusher")
+		}
+		if _, ok := w.(http.Hijacker); !ok {
+			t.Error("the recorder hides http.Hijacker")
+		}
+	})
+	handler := withMetrics(mux)
+
+	tests := []struct {
+		path   string
+		route  string
+		method string
+		code   string
+	}{
+		{"/threads/10", "GET /threads/{id}", "GET", "200"},
+		{"/threads/999", "GET /threads/{id}", "GET", "404"},
+		{"/stream", "GET /stream", "GET", "200"},
+		{"/nowhere", "unmatched", "GET", "404"},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			before := requestCount(t, test.route, test.method, test.code)
+			// A real server so the writer is one that can flush and hijack.
+			server := httptest.NewServer(handler)
+			defer server.Close()
+			response, err := http.Get(server.URL + test.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+
+			if after := requestCount(t, test.route, test.method, test.code); after != before+1 {
+				t.Errorf("route %q, code %s: count went from %d to %d, want one more", test.route, test.code, before, after)
+			}
+		})
+	}
+}
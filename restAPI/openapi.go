@@ -0,0 +1,311 @@
+package restAPI
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OpenAPI 3 document describing the REST API. It is built from the same route table the handlers are registered
+// from, so the served spec and the client generated from it can't drift from the handlers.
+type OpenAPI struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Operations of a path keyed by lower case HTTP method.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	Paged       bool                `json:"x-paged,omitempty"` // Accepts the query parameters of query.go and answers with a Page.
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	GoType               string             `json:"x-go-type,omitempty"` // Go type the schema was derived from, used by the client generator.
+}
+
+// Documentation registered alongside each handler.
+type operation struct {
+	ID            string
+	Summary       string
+	PathParams    []Parameter
+	QueryParams   []Parameter // Filters; the shared pagination parameters are added when Paged is set.
+	Response      any         // Zero value of the 200 body, used to derive its schema. For paged routes, a slice of the items.
+	ContentType   string      // Media type of the 200 body, application/json when empty.
+	Streaming     bool        // Written incrementally, so the response bypasses buffering for caching and compression.
+	Paged         bool
+	Errors        []int
+	ErrorResponse any // Zero value of the error bodies when they aren't an APIError.
+}
+
+// Registers handlers on a multiplexer and records the documentation of each one for the OpenAPI document.
+type router struct {
+	mux       *http.ServeMux
+	routes    []route
+	streaming map[string]bool // Patterns of streaming routes.
+}
+
+type route struct {
+	method string
+	path   string
+	op     operation
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Registers handler for pattern (e.g. "GET /threads/{id}") and documents it with op.
+// Panics if the path parameters of the pattern and the documentation disagree, so mismatches fail at startup.
+func (router *router) handle(pattern string, op operation, handler http.HandlerFunc) {
+	method, routePath, ok := strings.Cut(pattern, " ")
+	if !ok {
+		panic(fmt.Sprintf("restAPI: route %q must include a method", pattern))
+	}
+
+	var inPattern, documented []string
+	for _, match := range pathParamPattern.FindAllStringSubmatch(routePath, -1) {
+		inPattern = append(inPattern, match[1])
+	}
+	for _, param := range op.PathParams {
+		documented = append(documented, param.Name)
+	}
+	if !slices.Equal(inPattern, documented) {
+		panic(fmt.Sprintf("restAPI: route %q documents path parameters %v but has %v", pattern, documented, inPattern))
+	}
+
+	router.mux.HandleFunc(pattern, handler)
+	if op.Streaming {
+		if router.streaming == nil {
+			router.streaming = map[string]bool{}
+		}
+		router.streaming[pattern] = true
+	}
+	router.routes = append(router.routes, route{method: method, path: routePath, op: op})
+}
+
+// Registers a streaming handler the OpenAPI document can't describe, such as a WebSocket upgrade.
+func (router *router) handleStream(pattern string, handler http.Handler) {
+	router.mux.Handle(pattern, handler)
+	if router.streaming == nil {
+		router.streaming = map[string]bool{}
+	}
+	router.streaming[pattern] = true
+}
+
+// Builds the OpenAPI document for every documented route.
+func (router *router) openAPI() *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI:    "3.0.3",
+		Info:       Info{Title: "VLRScrape REST API", Version: "1.0.0"},
+		Paths:      map[string]PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+	schemaOf := func(value any) *Schema {
+		return schemaFor(reflect.TypeOf(value), doc.Components.Schemas)
+	}
+	errorSchema := schemaOf(APIError{})
+
+	for _, route := range router.routes {
+		op := &Operation{
+			OperationID: route.op.ID,
+			Summary:     route.op.Summary,
+			Responses:   map[string]Response{},
+			Paged:       route.op.Paged,
+		}
+
+		for _, param := range route.op.PathParams {
+			param.In = "path"
+			param.Required = true
+			op.Parameters = append(op.Parameters, param)
+		}
+		queryParams := route.op.QueryParams
+		if route.op.Paged {
+			queryParams = append(queryParams, pageParams...)
+		}
+		for _, param := range queryParams {
+			param.In = "query"
+			op.Parameters = append(op.Parameters, param)
+		}
+
+		body := schemaOf(route.op.Response)
+		if route.op.Paged {
+			body = pageSchema(body, schemaOf(PageMeta{}))
+		}
+		contentType := route.op.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		op.Responses["200"] = Response{
+			Description: "OK",
+			Content:     map[string]MediaType{contentType: {Schema: body}},
+		}
+		errorBody := errorSchema
+		if route.op.ErrorResponse != nil {
+			errorBody = schemaOf(route.op.ErrorResponse)
+		}
+		for _, status := range route.op.Errors {
+			op.Responses[fmt.Sprint(status)] = Response{
+				Description: http.StatusText(status),
+				Content:     map[string]MediaType{"application/json": {Schema: errorBody}},
+			}
+		}
+
+		if doc.Paths[route.path] == nil {
+			doc.Paths[route.path] = PathItem{}
+		}
+		doc.Paths[route.path][strings.ToLower(route.method)] = op
+	}
+
+	return doc
+}
+
+// Describes a Page whose data holds the items of the slice schema items.
+func pageSchema(items *Schema, meta *Schema) *Schema {
+	return &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"data": items, "meta": meta},
+		Required:   []string{"data", "meta"},
+	}
+}
+
+// Query parameters accepted by every paged operation.
+var pageParams = []Parameter{
+	{Name: "sort", Description: "Comma separated fields to sort by, prefixed with - for descending order.", Schema: &Schema{Type: "string"}},
+	{Name: "limit", Description: fmt.Sprintf("Page size between 1 and %d, %d by default.", maxLimit, defaultLimit), Schema: &Schema{Type: "integer"}},
+	{Name: "offset", Description: "Number of items to skip. Cannot be combined with cursor.", Schema: &Schema{Type: "integer"}},
+	{Name: "cursor", Description: "meta.next_cursor of the previous page.", Schema: &Schema{Type: "string"}},
+	{Name: "fields", Description: "Comma separated fields to include in each item.", Schema: &Schema{Type: "string"}},
+}
+
+// Documents the filters of a type as query parameters, in alphabetical order.
+func filterParams[T any](filters map[string]filter[T]) []Parameter {
+	var params []Parameter
+	for name := range filters {
+		param := Parameter{Name: name, Schema: &Schema{Type: "string"}}
+		switch {
+		case strings.HasPrefix(name, "min_"):
+			param.Description = "Minimum " + strings.TrimPrefix(name, "min_") + "."
+			param.Schema.Type = "integer"
+		case strings.HasPrefix(name, "max_"):
+			param.Description = "Maximum " + strings.TrimPrefix(name, "max_") + "."
+			param.Schema.Type = "integer"
+		default:
+			param.Description = "Case insensitive substring of the " + name + "."
+		}
+		params = append(params, param)
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].Name < params[j].Name })
+	return params
+}
+
+// Derives the schema of a Go type from its JSON encoding. Named structs are added to components and referenced.
+func schemaFor(goType reflect.Type, components map[string]*Schema) *Schema {
+	if goType == nil {
+		return &Schema{}
+	}
+	if goType == reflect.TypeFor[time.Time]() {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch goType.Kind() {
+	case reflect.Pointer:
+		return schemaFor(goType.Elem(), components)
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaFor(goType.Elem(), components)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaFor(goType.Elem(), components)}
+	case reflect.Struct:
+		name := goType.Name()
+		if _, ok := components[name]; !ok {
+			schema := &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{},
+				GoType:     path.Base(goType.PkgPath()) + "." + name,
+			}
+			components[name] = schema // Registered before the fields so recursive types terminate.
+			addProperties(schema, goType, components)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// Adds the JSON fields of a struct to schema, flattening embedded structs the way encoding/json does.
+func addProperties(schema *Schema, goType reflect.Type, components map[string]*Schema) {
+	for i := 0; i < goType.NumField(); i++ {
+		field := goType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addProperties(schema, field.Type, components)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = schemaFor(field.Type, components)
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package restAPI
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+)
+
+// Serves the fixture output directory under testdata, which holds two threads snapshots, one matches snapshot and two regions.
+func newTestHandler(t *testing.T) (http.Handler, *OpenAPI) {
+	t.Helper()
+	outputDir := filepath.Join("testdata", "output")
+	sections := map[string]string{"threads": "outputThreads", "matches": "outputMatches"}
+	return NewHandler(outputDir, filepath.Join(outputDir, "ranking"), sections, NewLiveFeed(), nil, config.Default().Server.WebSocket)
+}
+
+// Every documented operation answers with a documented status and a body matching the schema of that status.
+func TestHandlerMatchesOpenAPI(t *testing.T) {
+	handler, doc := newTestHandler(t)
+
+	tests := []struct {
+		operationID string
+		method      string
+		target      string
+		body        string
+		status      int
+	}{
+		{"getThreads", "GET", "/threads", "", http.StatusOK},
+		{"getThreads", "GET", "/threads?min_frags=5&sort=-frag_count&limit=1", "", http.StatusOK},
+		{"getThreads", "GET", "/threads?sort=-frags_per_hour", "", http.StatusOK},
+		{"getThreads", "GET", "/threads?unknown=1", "", http.StatusOK},
+		{"getThreads", "GET", "/threads?limit=0", "", http.StatusBadRequest},
+		{"getThreads", "GET", "/threads?cursor=garbage", "", http.StatusBadRequest},
+		{"listThreadsSnapshots", "GET", "/threads/snapshots", "", http.StatusOK},
+		{"getThreadsSnapshot", "GET", "/threads/at/2024-11-05_15-04-05", "", http.StatusOK},
+		{"getThreadsSnapshot", "GET", "/threads/at/yesterday", "", http.StatusBadRequest},
+		{"getThreadsSnapshot", "GET", "/threads/at/2020-01-01_00-00-00", "", http.StatusNotFound},
+		{"getThread", "GET", "/threads/10", "", http.StatusOK},
+		{"getThread", "GET", "/threads/9", "", http.StatusOK},
+		{"getThread", "GET", "/threads/ten", "", http.StatusBadRequest},
+		{"getThread", "GET", "/threads/999", "", http.StatusNotFound},
+		{"getMatches", "GET", "/matches", "", http.StatusOK},
+		{"getMatches", "GET", "/matches?team=fnatic&sort=id", "", http.StatusOK},
+		{"listMatchesSnapshots", "GET", "/matches/snapshots", "", http.StatusOK},
+		{"getMatchesSnapshot", "GET", "/matches/at/2024-11-06_15-04-05", "", http.StatusOK},
+		{"getMatch", "GET", "/matches/101", "", http.StatusOK},
+		{"getMatch", "GET", "/matches/999", "", http.StatusNotFound},
+		{"getTeam", "GET", "/teams/fnatic", "", http.StatusOK},
+		{"getTeam", "GET", "/teams/nobody", "", http.StatusNotFound},
+		{"getTeam", "GET", "/teams/team%20liquid", "", http.StatusBadRequest},
+		{"getRanking", "GET", "/Ranking/Europe", "", http.StatusOK},
+		{"getRanking", "GET", "/Ranking/Europe?min_elo=1800", "", http.StatusOK},
+		{"getRanking", "GET", "/Ranking/Atlantis", "", http.StatusNotFound},
+		{"getRanking", "GET", "/Ranking/Europe?offset=-1", "", http.StatusBadRequest},
+		{"queryGraphQL", "POST", "/graphql", `{"query": "{ threads { id title } matches { id } }"}`, http.StatusOK},
+		{"queryGraphQL", "POST", "/graphql", `{"query": "{ nonsense }"}`, http.StatusBadRequest},
+		{"queryGraphQL", "POST", "/graphql", `not json`, http.StatusBadRequest},
+		{"queryGraphQL", "POST", "/graphql", `{}`, http.StatusBadRequest},
+	}
+
+	operations := map[string]*Operation{}
+	paths := map[string]string{}
+	for path, item := range doc.Paths {
+		for _, op := range item {
+			operations[op.OperationID] = op
+			paths[op.OperationID] = path
+		}
+	}
+
+	covered := map[string]bool{}
+	for _, test := range tests {
+		t.Run(test.method+" "+test.target, func(t *testing.T) {
+			op := operations[test.operationID]
+			if op == nil {
+				t.Fatalf("operation %s is not documented", test.operationID)
+			}
+
+			request := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, request)
+
+			if response.Code != test.status {
+				t.Fatalf("status = %d, want %d; body: %s", response.Code, test.status, response.Body)
+			}
+			documented, ok := op.Responses[fmt.Sprint(response.Code)]
+			if !ok {
+				t.Fatalf("status %d is not documented for %s %s", response.Code, test.operationID, paths[test.operationID])
+			}
+			media, ok := documented.Content["application/json"]
+			if !ok {
+				t.Fatalf("status %d of %s is not documented as JSON", response.Code, test.operationID)
+			}
+
+			var body any
+			if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON body: %v", err)
+			}
+			for _, problem := range validate(doc, media.Schema, body, "body") {
+				t.Error(problem)
+			}
+			if response.Code == http.StatusOK {
+				covered[test.operationID] = true
+			}
+		})
+	}
+
+	// The stream is checked on its own below; every other operation needs a successful case above.
+	covered["streamLiveMatches"] = true
+	var missing []string
+	for id := range operations {
+		if !covered[id] {
+			missing = append(missing, id)
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Errorf("no successful test case for %s", strings.Join(missing, ", "))
+	}
+}
+
+func TestLiveStreamMatchesOpenAPI(t *testing.T) {
+	handler, doc := newTestHandler(t)
+
+	op := doc.Paths["/matches/live/stream"]["get"]
+	if op == nil {
+		t.Fatal("streamLiveMatches is not documented")
+	}
+	contentType := ""
+	for media := range op.Responses["200"].Content {
+		contentType = media
+	}
+
+	// A cancelled request ends the stream once its headers are written.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	request := httptest.NewRequest("GET", "/matches/live/stream", nil).WithContext(ctx)
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", response.Code, http.StatusOK)
+	}
+	if got := response.Header().Get("Content-Type"); !strings.HasPrefix(got, contentType) {
+		t.Errorf("Content-Type = %q, want the documented %q", got, contentType)
+	}
+
+	request = httptest.NewRequest("GET", "/matches/live/stream?last_event_id=soon", nil)
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	if _, ok := op.Responses[fmt.Sprint(response.Code)]; !ok || response.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want the documented %d", response.Code, http.StatusBadRequest)
+	}
+}
+
+// Lists every way value departs from schema. Objects may not hold properties the schema doesn't declare.
+func validate(doc *OpenAPI, schema *Schema, value any, at string) []string {
+	if schema.Ref != "" {
+		resolved, ok := doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if !ok {
+			return []string{fmt.Sprintf("%s: unresolved reference %s", at, schema.Ref)}
+		}
+		schema = resolved
+	}
+	if schema.Type == "" {
+		return nil
+	}
+	if value == nil {
+		// Optional pointers, slices and maps encode as null when they aren't omitted.
+		if schema.Type == "array" || schema.Type == "object" {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s: null where %s is expected", at, schema.Type)}
+	}
+
+	mismatch := []string{fmt.Sprintf("%s: %T where %s is expected", at, value, schema.Type)}
+	switch schema.Type {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return mismatch
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return mismatch
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != float64(int64(number)) {
+			return mismatch
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return mismatch
+		}
+		var problems []string
+		for i, item := range items {
+			problems = append(problems, validate(doc, schema.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+		return problems
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return mismatch
+		}
+		var problems []string
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing required %s", at, name))
+			}
+		}
+		for name, field := range object {
+			fieldSchema, ok := schema.Properties[name]
+			if !ok {
+				fieldSchema = schema.AdditionalProperties
+			}
+			if fieldSchema == nil {
+				problems = append(problems, fmt.Sprintf("%s: undocumented property %s", at, name))
+				continue
+			}
+			problems = append(problems, validate(doc, fieldSchema, field, at+"."+name)...)
+		}
+		return problems
+	}
+	return nil
+}
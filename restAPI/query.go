@@ -0,0 +1,327 @@
+package restAPI
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mrovengerdev/vlrscrape/model"
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+// Every queryable endpoint takes sort, limit, offset, cursor and fields (see pageParams). Other parameters are
+// filters of the endpoint's type, and parameters that are neither, such as cache busters, are ignored.
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+)
+
+// Response body for paged endpoints. Data holds the items of the requested page, projected to ?fields= when given.
+type Page struct {
+	Data []any    `json:"data"`
+	Meta PageMeta `json:"meta"`
+}
+
+type PageMeta = model.PageMeta
+
+// Returned for malformed or unknown query parameters and reported to the client as a 400.
+type queryError struct {
+	message string
+}
+
+func (err *queryError) Error() string {
+	return err.message
+}
+
+func newQueryError(format string, args ...any) error {
+	return &queryError{message: fmt.Sprintf(format, args...)}
+}
+
+// Reports whether an item matches the value given for a filter parameter.
+type filter[T any] func(item T, value string) (bool, error)
+
+// Filters available per type, keyed by query parameter.
+var threadFilters = map[string]filter[scrape.Thread]{
+	"title":        containsFilter(func(thread scrape.Thread) []string { return []string{thread.Title} }),
+	"min_frags":    minFilter("min_frags", func(thread scrape.Thread) int { return thread.FragCount }),
+	"min_comments": minFilter("min_comments", func(thread scrape.Thread) int { return thread.CommentCount }),
+}
+
+var matchFilters = map[string]filter[scrape.Match]{
+	"team":       containsFilter(func(match scrape.Match) []string { return []string{match.Team1, match.Team2} }),
+	"tournament": containsFilter(func(match scrape.Match) []string { return []string{match.Tournament} }),
+}
+
+var rankingFilters = map[string]filter[scrape.Ranking]{
+	"team":    containsFilter(func(ranking scrape.Ranking) []string { return []string{ranking.TeamName} }),
+	"min_elo": minFilter("min_elo", func(ranking scrape.Ranking) int { return ranking.ELO }),
+	"max_rank": func(ranking scrape.Ranking, value string) (bool, error) {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return false, newQueryError("max_rank must be an integer")
+		}
+		return ranking.Rank <= limit, nil
+	},
+}
+
+// Matches items where any of the given fields contains the value, ignoring case.
+func containsFilter[T any](fields func(T) []string) filter[T] {
+	return func(item T, value string) (bool, error) {
+		for _, field := range fields(item) {
+			if strings.Contains(strings.ToLower(field), strings.ToLower(value)) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// Matches items where the field is at least the value of the query parameter param.
+func minFilter[T any](param string, field func(T) int) filter[T] {
+	return func(item T, value string) (bool, error) {
+		minimum, err := strconv.Atoi(value)
+		if err != nil {
+			return false, newQueryError("%s must be an integer", param)
+		}
+		return field(item) >= minimum, nil
+	}
+}
+
+// Runs the query in values over the section snapshot taken at timestamp.
+func querySection(section string, file []byte, values url.Values, timestamp string) (Page, error) {
+	switch section {
+	case "threads":
+		return runQuery(file, values, threadFilters, timestamp)
+	case "matches":
+		return runQuery(file, values, matchFilters, timestamp)
+	default:
+		return Page{}, fmt.Errorf("section %q has no query support", section)
+	}
+}
+
+// Returns the zero value of a section snapshot and its filters as query parameters, for documenting its routes.
+func sectionSchema(section string) (any, []Parameter) {
+	switch section {
+	case "threads":
+		return []scrape.Thread{}, filterParams(threadFilters)
+	case "matches":
+		return []scrape.Match{}, filterParams(matchFilters)
+	default:
+		return []any{}, nil
+	}
+}
+
+// Decodes a JSON array of T, then filters, sorts, paginates and projects it as described by values. version names
+// the data in file, e.g. a snapshot timestamp, so that cursors can't be used across versions.
+func runQuery[T any](file []byte, values url.Values, filters map[string]filter[T], version string) (Page, error) {
+	var items []T
+	if err := json.Unmarshal(file, &items); err != nil {
+		return Page{}, err
+	}
+
+	// Filtering
+	for param, given := range values {
+		match, ok := filters[param]
+		if !ok {
+			continue
+		}
+
+		var kept []T
+		for _, item := range items {
+			ok, err := match(item, given[0])
+			if err != nil {
+				return Page{}, err
+			}
+			if ok {
+				kept = append(kept, item)
+			}
+		}
+		items = kept
+	}
+
+	fields := jsonFields(reflect.TypeFor[T]())
+
+	// Sorting, e.g. ?sort=-frag_count,title
+	if sortParam := values.Get("sort"); sortParam != "" {
+		type sortKey struct {
+			index      int
+			descending bool
+		}
+		var keys []sortKey
+		for _, name := range strings.Split(sortParam, ",") {
+			descending := strings.HasPrefix(name, "-")
+			name = strings.TrimPrefix(name, "-")
+			index, ok := fields[name]
+			if !ok {
+				return Page{}, newQueryError("cannot sort by unknown field %q", name)
+			}
+			keys = append(keys, sortKey{index: index, descending: descending})
+		}
+
+		sort.SliceStable(items, func(i, j int) bool {
+			left, right := reflect.ValueOf(items[i]), reflect.ValueOf(items[j])
+			for _, key := range keys {
+				order := compareValues(left.Field(key.index), right.Field(key.index))
+				if order == 0 {
+					continue
+				}
+				if key.descending {
+					return order > 0
+				}
+				return order < 0
+			}
+			return false
+		})
+	}
+
+	// Pagination by offset or by the cursor from a previous page.
+	limit, offset, err := pageBounds(values, version)
+	if err != nil {
+		return Page{}, err
+	}
+
+	total := len(items)
+	end := min(offset+limit, total)
+	start := min(offset, total)
+	pageItems := items[start:end]
+
+	// Projection, e.g. ?fields=id,title
+	var projected []string
+	if fieldsParam := values.Get("fields"); fieldsParam != "" {
+		for _, name := range strings.Split(fieldsParam, ",") {
+			if _, ok := fields[name]; !ok {
+				return Page{}, newQueryError("unknown field %q", name)
+			}
+			projected = append(projected, name)
+		}
+	}
+
+	data := make([]any, 0, len(pageItems))
+	for _, item := range pageItems {
+		if projected == nil {
+			data = append(data, item)
+			continue
+		}
+		value := reflect.ValueOf(item)
+		entry := make(map[string]any, len(projected))
+		for _, name := range projected {
+			entry[name] = value.Field(fields[name]).Interface()
+		}
+		data = append(data, entry)
+	}
+
+	meta := PageMeta{
+		Total:  total,
+		Count:  len(data),
+		Offset: start,
+		Limit:  limit,
+	}
+	if end < total {
+		meta.NextCursor = encodeCursor(version, end)
+	}
+
+	return Page{Data: data, Meta: meta}, nil
+}
+
+// Reads limit and offset from ?limit=, ?offset= and ?cursor=, applying the default and maximum limit.
+// A cursor issued for another version of the data is rejected rather than skipping or repeating items.
+func pageBounds(values url.Values, version string) (limit int, offset int, err error) {
+	limit = defaultLimit
+	if limitParam := values.Get("limit"); limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > maxLimit {
+			return 0, 0, newQueryError("limit must be an integer between 1 and %d", maxLimit)
+		}
+	}
+
+	if values.Has("cursor") && values.Has("offset") {
+		return 0, 0, newQueryError("use either offset or cursor, not both")
+	}
+	if offsetParam := values.Get("offset"); offsetParam != "" {
+		offset, err = strconv.Atoi(offsetParam)
+		if err != nil || offset < 0 {
+			return 0, 0, newQueryError("offset must be a non-negative integer")
+		}
+	}
+	if cursor := values.Get("cursor"); cursor != "" {
+		var issuedFor string
+		issuedFor, offset, err = decodeCursor(cursor)
+		if err != nil {
+			return 0, 0, newQueryError("invalid cursor")
+		}
+		if issuedFor != version {
+			return 0, 0, newQueryError("cursor belongs to another snapshot or to rankings that have since been rescraped, start again without it")
+		}
+	}
+
+	return limit, offset, nil
+}
+
+// Cursors are opaque to clients so the pagination scheme can change without breaking them. They carry the version
+// of the data they were issued for, such as a snapshot timestamp, along with the offset of the next page.
+func encodeCursor(version string, offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(version + "@" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (version string, offset int, err error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", 0, err
+	}
+	version, text, ok := strings.Cut(string(decoded), "@")
+	if !ok {
+		return "", 0, fmt.Errorf("malformed cursor")
+	}
+	offset, err = strconv.Atoi(text)
+	if err != nil || offset < 0 {
+		return "", 0, fmt.Errorf("malformed cursor")
+	}
+	return version, offset, nil
+}
+
+// Returns the version a ?cursor= was issued for, or "" when there is no valid cursor.
+func cursorVersion(values url.Values) string {
+	version, _, err := decodeCursor(values.Get("cursor"))
+	if err != nil {
+		return ""
+	}
+	return version
+}
+
+// Maps the JSON name of each field of a struct type to its index.
+func jsonFields(structType reflect.Type) map[string]int {
+	fields := make(map[string]int, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		name, _, _ := strings.Cut(structType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = i
+		}
+	}
+	return fields
+}
+
+// Orders two field values of the same kind, returning -1, 0 or 1. Nil pointers, such as an unknown published_at,
+// sort before any value, so they come last in descending order.
+func compareValues(left reflect.Value, right reflect.Value) int {
+	switch left.Kind() {
+	case reflect.Pointer:
+		if left.IsNil() || right.IsNil() {
+			return cmp.Compare(strconv.FormatBool(!left.IsNil()), strconv.FormatBool(!right.IsNil()))
+		}
+		return compareValues(left.Elem(), right.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(left.Int(), right.Int())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(left.Float(), right.Float())
+	case reflect.Bool:
+		return cmp.Compare(strconv.FormatBool(left.Bool()), strconv.FormatBool(right.Bool()))
+	default:
+		return cmp.Compare(strings.ToLower(fmt.Sprint(left.Interface())), strings.ToLower(fmt.Sprint(right.Interface())))
+	}
+}
@@ -0,0 +1,98 @@
+package restAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+func TestRunQuery(t *testing.T) {
+	file := []byte(`[
+		{"id": 1, "title": "VCT Champions", "frag_count": 10, "comment_count": 4, "published_at": "2024-11-06T10:00:00Z"},
+		{"id": 2, "title": "Patch notes", "frag_count": 30, "comment_count": 1},
+		{"id": 3, "title": "champions recap", "frag_count": 10, "comment_count": 9, "published_at": "2024-11-07T10:00:00Z"},
+		{"id": 4, "title": "Roster moves", "frag_count": -2, "comment_count": 0, "published_at": "2024-11-05T10:00:00Z"}
+	]`)
+	cursor := encodeCursor("v1", 2)
+	tests := []struct {
+		name    string
+		query   string
+		version string
+		data    string // JSON of the page's items; only their ids unless ?fields= is given.
+		meta    PageMeta
+		invalid bool   // Whether a queryError is expected.
+		message string // Its message, when it matters.
+	}{
+		{name: "everything", data: `[1,2,3,4]`, meta: PageMeta{Total: 4, Count: 4, Limit: defaultLimit}},
+		{name: "contains ignoring case", query: "title=CHAMPIONS", data: `[1,3]`, meta: PageMeta{Total: 2, Count: 2, Limit: defaultLimit}},
+		{name: "filters combine", query: "title=champions&min_comments=5", data: `[3]`, meta: PageMeta{Total: 1, Count: 1, Limit: defaultLimit}},
+		{name: "unknown parameters ignored", query: "_=123", data: `[1,2,3,4]`, meta: PageMeta{Total: 4, Count: 4, Limit: defaultLimit}},
+		{name: "sort descending then ascending", query: "sort=-frag_count,title", data: `[2,3,1,4]`, meta: PageMeta{Total: 4, Count: 4, Limit: defaultLimit}},
+		{name: "nil sorts first", query: "sort=published_at", data: `[2,4,1,3]`, meta: PageMeta{Total: 4, Count: 4, Limit: defaultLimit}},
+		{name: "nil sorts last descending", query: "sort=-published_at", data: `[3,1,4,2]`, meta: PageMeta{Total: 4, Count: 4, Limit: defaultLimit}},
+		{name: "first page", query: "limit=2", version: "v1", data: `[1,2]`, meta: PageMeta{Total: 4, Count: 2, Limit: 2, NextCursor: cursor}},
+		{name: "cursor", query: "limit=2&cursor=" + cursor, version: "v1", data: `[3,4]`, meta: PageMeta{Total: 4, Count: 2, Offset: 2, Limit: 2}},
+		{name: "offset past the end", query: "offset=10", data: `[]`, meta: PageMeta{Total: 4, Offset: 4, Limit: defaultLimit}},
+		{name: "projection", query: "fields=id,title&limit=1", version: "v1", data: `[{"id":1,"title":"VCT Champions"}]`, meta: PageMeta{Total: 4, Count: 1, Limit: 1, NextCursor: encodeCursor("v1", 1)}},
+		{name: "cursor of another version", query: "cursor=" + cursor, version: "v2", invalid: true},
+		{name: "malformed cursor", query: "cursor=%25%25", invalid: true},
+		{name: "cursor and offset", query: "offset=1&cursor=" + cursor, version: "v1", invalid: true},
+		{name: "limit too large", query: "limit=501", invalid: true},
+		{name: "limit zero", query: "limit=0", invalid: true},
+		{name: "negative offset", query: "offset=-1", invalid: true},
+		{name: "non-numeric filter", query: "min_frags=many", invalid: true, message: "min_frags must be an integer"},
+		{name: "unknown sort field", query: "sort=views", invalid: true},
+		{name: "unknown projected field", query: "fields=id,views", invalid: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values, err := url.ParseQuery(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			page, err := runQuery(file, values, threadFilters, test.version)
+			var queryErr *queryError
+			if test.invalid {
+				if !errors.As(err, &queryErr) {
+					t.Fatalf("runQuery returned %v, want a queryError", err)
+				}
+				if test.message != "" && err.Error() != test.message {
+					t.Errorf("error %q, want %q", err, test.message)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var data any = page.Data
+			if !values.Has("fields") {
+				ids := []int{}
+				for _, item := range page.Data {
+					ids = append(ids, item.(scrape.Thread).ID)
+				}
+				data = ids
+			}
+			encoded, err := json.Marshal(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(encoded) != test.data {
+				t.Errorf("data = %s, want %s", encoded, test.data)
+			}
+			if page.Meta != test.meta {
+				t.Errorf("meta = %+v, want %+v", page.Meta, test.meta)
+			}
+		})
+	}
+}
+
+func TestRunQueryMalformedFile(t *testing.T) {
+	var queryErr *queryError
+	if _, err := runQuery([]byte(`{"not": "a list"}`), url.Values{}, threadFilters, ""); err == nil || errors.As(err, &queryErr) {
+		t.Errorf("runQuery returned %v for a malformed file, want a decoding error", err)
+	}
+}
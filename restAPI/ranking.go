@@ -0,0 +1,81 @@
+package restAPI
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Returned when no rankings have been scraped for a region.
+var ErrRegionNotFound = errors.New("region not found")
+
+// Names accepted in request paths, matching regions as written by scrape.AllRankingScrape (e.g. "Asia-Pacific").
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9]+(-[A-Za-z0-9]+)*$`)
+
+// Resolves region names to the ranking files stored in the ranking folder.
+// Only regions with an existing file are served, so a region can never point outside the folder.
+type RegionIndex struct {
+	rankingDir string
+}
+
+func NewRegionIndex(rankingDir string) *RegionIndex {
+	return &RegionIndex{rankingDir: rankingDir}
+}
+
+// Reports whether a path value is well formed, regardless of whether anything exists under that name.
+func validName(name string) bool {
+	return namePattern.MatchString(name)
+}
+
+// Lists every region that has rankings in alphabetical order.
+func (index *RegionIndex) Regions() ([]string, error) {
+	entries, err := os.ReadDir(index.rankingDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	regions := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "output") || !strings.HasSuffix(name, "Rankings.json") {
+			continue
+		}
+		region := strings.TrimSuffix(strings.TrimPrefix(name, "output"), "Rankings.json")
+		if validName(region) {
+			regions = append(regions, region)
+		}
+	}
+	sort.Strings(regions)
+
+	return regions, nil
+}
+
+// Reads the rankings of a known region.
+func (index *RegionIndex) Read(region string) ([]byte, error) {
+	regions, err := index.Regions()
+	if err != nil {
+		return nil, err
+	}
+	for _, known := range regions {
+		if known == region {
+			return os.ReadFile(filepath.Join(index.rankingDir, "output"+known+"Rankings.json"))
+		}
+	}
+	return nil, ErrRegionNotFound
+}
+
+// Returns when the rankings of a region were last written, or the zero time if unknown.
+func (index *RegionIndex) ModTime(region string) time.Time {
+	info, err := os.Stat(filepath.Join(index.rankingDir, "output"+region+"Rankings.json"))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
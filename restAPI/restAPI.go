@@ -1,10 +1,17 @@
 package restAPI
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/model"
+	"github.com/mrovengerdev/vlrscrape/scrape"
 )
 
 // List of GET endpoints:
@@ -13,7 +20,22 @@ import (
 http://localhost:8080/{dataObject}
 http://localhost:8080/threads
 http://localhost:8080/matches
-http://localhost:8080/rankings
+
+http://localhost:8080/{section}/snapshots
+http://localhost:8080/threads/snapshots
+http://localhost:8080/matches/snapshots
+
+http://localhost:8080/{section}/at/{timestamp}
+http://localhost:8080/threads/at/2024-11-06_15-04-05
+
+http://localhost:8080/threads/{id}
+http://localhost:8080/matches/{id}
+http://localhost:8080/teams/{slug}
+http://localhost:8080/teams/sentinels
+
+http://localhost:8080/matches/live/stream (Server-Sent Events)
+
+ws://localhost:8080/ws (WebSocket subscriptions to topics such as match:12345, rankings:Europe and threads:new)
 
 http://localhost:8080/Ranking/{region}
 http://localhost:8080/Ranking/Asia-Pacific
@@ -23,57 +45,313 @@ http://localhost:8080/Ranking/Brazil
 http://localhost:8080/Ranking/Korea
 http://localhost:8080/Ranking/Japan
 etc...
-*/
 
-// Creates and maintains localhost that listens for GET requests and outputs the specified JSON file stored within the output folder.
-func Get() {
-	fmt.Println("REST API now operating...")
-	fmt.Println("Use GET at: http://localhost:8080/")
-	fmt.Println("Endpoint: http://localhost:8080/{dataObject}")
-	fmt.Println("Example: http://localhost:8080/threads")
-	fmt.Println("Endpoint: http://localhost:8080/Ranking/{region}")
-	fmt.Println("Example: http://localhost:8080/Ranking/Asia-Pacific")
+http://localhost:8080/openapi.json
+
+POST http://localhost:8080/graphql
+
+http://localhost:8080/healthz
+http://localhost:8080/readyz (503 until a scrape has finished, or when the latest one got no data for a section or the data is stale)
 
+Section and ranking endpoints answer with a page, {"data": [...], "meta": {...}}, shaped by query parameters:
+http://localhost:8080/threads?min_frags=10&sort=-frag_count&limit=20
+http://localhost:8080/threads?sort=-frags_per_hour&limit=10 (trending threads)
+http://localhost:8080/matches?team=Sentinels&tournament=Champions&fields=id,team1,team2
+http://localhost:8080/Ranking/Europe?min_elo=1500&cursor={meta.next_cursor}
+Cursors stay on the snapshot they were issued for, even once a newer one has been scraped.
+*/
+
+// Builds the handler serving every endpoint along with the OpenAPI document describing them.
+// sections maps each paged section (e.g. "threads") to its output file prefix so the newest snapshot can be served.
+// The live match stream is only served when live is non-nil, and the WebSocket API, limited by ws, when changes is non-nil.
+func NewHandler(outputDir string, rankingDir string, sections map[string]string, live *LiveFeed, changes *ChangeHub, ws config.WebSocket) (http.Handler, *OpenAPI) {
 	mux := http.NewServeMux()
+	router := &router{mux: mux}
+	snapshots := NewSnapshotIndex(outputDir, sections)
+	regions := NewRegionIndex(rankingDir)
+
+	// Each section resolves to its newest snapshot, with its history listed and retrievable by timestamp.
+	for _, section := range snapshots.Sections() {
+		name := strings.ToUpper(section[:1]) + section[1:]
+		response, filters := sectionSchema(section)
+
+		router.handle("GET /"+section, operation{
+			ID:          "get" + name,
+			Summary:     "Newest " + section + " snapshot.",
+			QueryParams: filters,
+			Response:    response,
+			Paged:       true,
+			Errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+		}, func(w http.ResponseWriter, r *http.Request) {
+			snapshot, err := latestOrCursor(snapshots, section, r.URL.Query())
+			serveSnapshot(w, r, section, snapshot, err)
+		})
+
+		router.handle("GET /"+section+"/snapshots", operation{
+			ID:       "list" + name + "Snapshots",
+			Summary:  "Every " + section + " snapshot, newest first.",
+			Response: []model.Snapshot{},
+			Errors:   []int{http.StatusInternalServerError},
+		}, func(w http.ResponseWriter, r *http.Request) {
+			list, err := snapshots.List(section)
+			if err != nil {
+				writeInternalError(w, err)
+				return
+			}
+			if len(list) > 0 {
+				setLastModified(w, list[0].ScrapedAt)
+			}
+
+			writeJSON(w, http.StatusOK, list)
+		})
+
+		router.handle("GET /"+section+"/at/{timestamp}", operation{
+			ID:          "get" + name + "Snapshot",
+			Summary:     "The " + section + " snapshot taken at a timestamp.",
+			PathParams:  []Parameter{{Name: "timestamp", Description: "Scrape time formatted as " + scrape.TimestampLayout + ".", Schema: &Schema{Type: "string"}}},
+			QueryParams: filters,
+			Response:    response,
+			Paged:       true,
+			Errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+		}, func(w http.ResponseWriter, r *http.Request) {
+			timestamp := r.PathValue("timestamp")
+			if _, err := time.Parse(scrape.TimestampLayout, timestamp); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("timestamp %q must use the layout %s", timestamp, scrape.TimestampLayout))
+				return
+			}
+
+			snapshot, err := snapshots.At(section, timestamp)
+			serveSnapshot(w, r, section, snapshot, err)
+		})
+	}
+
+	// Single records by ID, searched for in the newest snapshot first.
+	if _, ok := sections["threads"]; ok {
+		router.handle("GET /threads/{id}", operation{
+			ID:         "getThread",
+			Summary:    "A single thread by ID.",
+			PathParams: []Parameter{{Name: "id", Schema: &Schema{Type: "integer"}}},
+			Response:   scrape.Thread{},
+			Errors:     []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+		}, func(w http.ResponseWriter, r *http.Request) {
+			id, err := strconv.Atoi(r.PathValue("id"))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("thread id %q must be an integer", r.PathValue("id")))
+				return
+			}
+
+			thread, err := findThread(snapshots, id)
+			writeEntity(w, fmt.Sprintf("thread %d", id), thread, err)
+		})
+	}
+
+	if _, ok := sections["matches"]; ok {
+		router.handle("GET /matches/{id}", operation{
+			ID:         "getMatch",
+			Summary:    "A single match by ID with the current ranking of each team.",
+			PathParams: []Parameter{{Name: "id", Schema: &Schema{Type: "integer"}}},
+			Response:   MatchDetail{},
+			Errors:     []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+		}, func(w http.ResponseWriter, r *http.Request) {
+			id, err := strconv.Atoi(r.PathValue("id"))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("match id %q must be an integer", r.PathValue("id")))
+				return
+			}
+
+			match, err := findMatch(snapshots, regions, id)
+			writeEntity(w, fmt.Sprintf("match %d", id), match, err)
+		})
+	}
+
+	if _, ok := sections["matches"]; ok && live != nil {
+		router.handle("GET /matches/live/stream", operation{
+			ID:          "streamLiveMatches",
+			Summary:     "Server-Sent Events for matches going live, score changes and finished matches. Resume with the Last-Event-ID header.",
+			QueryParams: []Parameter{{Name: "last_event_id", Description: "Alternative to the Last-Event-ID header.", Schema: &Schema{Type: "integer"}}},
+			Response:    LiveEvent{},
+			ContentType: "text/event-stream",
+			Streaming:   true,
+			Errors:      []int{http.StatusBadRequest, http.StatusInternalServerError},
+		}, serveLiveStream(live))
+	}
+
+	// Subscriptions to change events, e.g. {"action": "subscribe", "topics": ["match:12345"]}. See websocket.go.
+	if changes != nil {
+		router.handleStream("GET /ws", serveWebSocket(changes, ws))
+	}
+
+	router.handle("GET /teams/{slug}", operation{
+		ID:         "getTeam",
+		Summary:    "A team with its regional rankings and upcoming matches.",
+		PathParams: []Parameter{{Name: "slug", Description: "Last segment of the team's vlr.gg URL, e.g. sentinels.", Schema: &Schema{Type: "string"}}},
+		Response:   Team{},
+		Errors:     []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		slug := r.PathValue("slug")
+		if !validName(slug) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid team slug %q", slug))
+			return
+		}
+
+		team, err := findTeam(snapshots, regions, slug)
+		writeEntity(w, fmt.Sprintf("team %q", slug), team, err)
+	})
+
+	// Queries over threads, matches, rankings and teams in one request, e.g. {"query": "{ matches { id teams { name ranking { rank } } } }"}.
+	graphQLSchema, err := newGraphQLSchema()
+	if err != nil {
+		panic(fmt.Sprintf("restAPI: invalid GraphQL schema: %v", err))
+	}
+	router.handle("POST /graphql", operation{
+		ID:            "queryGraphQL",
+		Summary:       fmt.Sprintf("GraphQL queries sent as a GraphQLRequest body. Queries are limited to a depth of %d and a complexity of %d.", graphQLMaxDepth, graphQLMaxComplexity),
+		Response:      GraphQLResponse{},
+		Errors:        []int{http.StatusBadRequest},
+		ErrorResponse: GraphQLResponse{},
+	}, serveGraphQL(graphQLSchema, snapshots, regions))
 
 	// Multiplexer matches requests to this server and can then intake a request
+	// Known sections are matched by the more specific routes above, so anything reaching here is unknown.
 	mux.HandleFunc("GET /{dataObject}", func(w http.ResponseWriter, r *http.Request) {
 		// Retrieve the dataObject from the request
 		dataObject := r.PathValue("dataObject")
 
-		// Sets the header to JSON
-		w.Header().Set("Content-Type", "application/json")
-
-		// Retrieve file from output folder
-		file, err := os.ReadFile("output/output" + dataObject + ".json")
-		if err != nil {
-			log.Fatalf("Error: %v", err)
+		if !validName(dataObject) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid data object %q", dataObject))
+			return
 		}
 
-		// Output the file
-		fmt.Fprintf(w, "%s", file)
-
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown data object %q, available: %s", dataObject, strings.Join(snapshots.Sections(), ", ")))
 	})
 
-	mux.HandleFunc("GET /Ranking/{region}", func(w http.ResponseWriter, r *http.Request) {
-		// Retrieve the dataObject from the request
+	router.handle("GET /Ranking/{region}", operation{
+		ID:          "getRanking",
+		Summary:     "Rankings of a region.",
+		PathParams:  []Parameter{{Name: "region", Description: "Region as written on vlr.gg, e.g. Asia-Pacific.", Schema: &Schema{Type: "string"}}},
+		QueryParams: filterParams(rankingFilters),
+		Response:    []scrape.Ranking{},
+		Paged:       true,
+		Errors:      []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		// Retrieve the region from the request
 		region := r.PathValue("region")
 
-		// Sets the header to JSON
-		w.Header().Set("Content-Type", "application/json")
+		if !validName(region) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid region %q", region))
+			return
+		}
 
-		// Retrieve file from output folder
-		file, err := os.ReadFile("output/ranking/output" + region + "Rankings.json")
+		// Retrieve file from ranking folder
+		file, err := regions.Read(region)
+		if errors.Is(err, ErrRegionNotFound) {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("no rankings for region %q", region))
+			return
+		}
 		if err != nil {
-			log.Fatalf("Error: %v", err)
+			writeInternalError(w, err)
+			return
 		}
 
-		// Output the file
-		fmt.Fprintf(w, "%s", file)
+		modTime := regions.ModTime(region)
+		setLastModified(w, modTime)
+
+		// Ranking files are rewritten in place, so their modification time tells cursors apart.
+		page, err := runQuery(file, r.URL.Query(), rankingFilters, strconv.FormatInt(modTime.UnixNano(), 10))
+		writePage(w, page, err)
+	})
+
+	// The document describing every route registered through the router above.
+	spec := router.openAPI()
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, spec)
 	})
 
-	// Listen and serve the server, passing in the multiplexer
-	if err := http.ListenAndServe(":8080", mux); err != nil {
-		fmt.Println(err.Error())
+	// Every other path gets a JSON 404 instead of the multiplexer's plain text one.
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no endpoint at %s", r.URL.Path))
+	})
+
+	return withCaching(mux, router.streaming), spec
+}
+
+// Returns the newest snapshot of a section, or the one the ?cursor= in values was issued for, so that paging
+// carries on through the same snapshot after a newer one has landed.
+func latestOrCursor(snapshots *SnapshotIndex, section string, values url.Values) (Snapshot, error) {
+	version := cursorVersion(values)
+	if version == "" {
+		return snapshots.Latest(section)
+	}
+	snapshot, err := snapshots.At(section, version)
+	if errors.Is(err, ErrSnapshotNotFound) {
+		return Snapshot{}, newQueryError("cursor is from a snapshot that is no longer kept, start again without it")
+	}
+	return snapshot, err
+}
+
+// Writes a page of a resolved snapshot, filtered, sorted and paginated through querySection, or a JSON error if
+// the lookup failed.
+func serveSnapshot(w http.ResponseWriter, r *http.Request, section string, snapshot Snapshot, err error) {
+	var badQuery *queryError
+	if errors.As(err, &badQuery) {
+		writeError(w, http.StatusBadRequest, badQuery.Error())
+		return
+	}
+	if errors.Is(err, ErrSnapshotNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no %s snapshot found", section))
+		return
+	}
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+
+	file, err := snapshot.Read()
+	if err == nil {
+		file, err = displaySnapshot(section, file, displayTime())
 	}
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+
+	// Threads and matches are displayed as of displayTime, so they last changed at the later of it and the scrape.
+	modified := snapshot.ScrapedAt
+	if now := displayTime(); displayedNow(section) && now.After(modified) {
+		modified = now
+	}
+	setLastModified(w, modified)
+
+	page, err := querySection(section, file, r.URL.Query(), snapshot.Timestamp)
+	writePage(w, page, err)
+}
+
+// Writes a queried page, reporting bad query parameters as a 400.
+func writePage(w http.ResponseWriter, page Page, err error) {
+	var badQuery *queryError
+	if errors.As(err, &badQuery) {
+		writeError(w, http.StatusBadRequest, badQuery.Error())
+		return
+	}
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+// Writes a single record, or a 404 naming it if it couldn't be found. Records may come from any snapshot, so they
+// are sent without Last-Modified and validated through the ETag alone.
+func writeEntity(w http.ResponseWriter, name string, entity any, err error) {
+	if errors.Is(err, ErrEntityNotFound) {
+		writeError(w, http.StatusNotFound, name+" not found")
+		return
+	}
+	if err != nil {
+		writeInternalError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entity)
 }
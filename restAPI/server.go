@@ -0,0 +1,214 @@
+package restAPI
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/metrics"
+)
+
+// Serves the REST API along with /healthz, /readyz and /metrics, which bypass API keys and rate limits
+// so load balancers, orchestrators and Prometheus can probe them.
+type Server struct {
+	http            *http.Server
+	tls             config.TLS
+	metrics         bool
+	shutdownTimeout time.Duration
+}
+
+// Builds the server from its settings. sections maps each paged section (e.g. "threads") to its output file prefix,
+// regional rankings are read from rankingDir and status reports the outcome of the scrapes to /readyz.
+// The live match stream is only served when live is non-nil, and the WebSocket API when changes is non-nil.
+// access authenticates and rate limits every request apart from /healthz, /readyz and /metrics.
+func NewServer(settings config.Server, access *AccessControl, outputDir string, rankingDir string, sections map[string]string, live *LiveFeed, changes *ChangeHub, status *ScrapeStatus) *Server {
+	api, _ := NewHandler(outputDir, rankingDir, sections, live, changes, settings.WebSocket)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		readiness := status.Readiness()
+		code := http.StatusOK
+		if !readiness.Ready {
+			code = http.StatusServiceUnavailable
+		}
+		writeJSON(w, code, readiness)
+	})
+	if settings.Metrics {
+		mux.Handle("GET /metrics", metrics.Handler())
+	}
+	mux.Handle("/", access.Wrap(api))
+
+	return &Server{
+		http: &http.Server{
+			Addr:         settings.Addr,
+			Handler:      withMetrics(mux),
+			ReadTimeout:  settings.ReadTimeout.Duration,
+			WriteTimeout: settings.WriteTimeout.Duration,
+			IdleTimeout:  settings.IdleTimeout.Duration,
+		},
+		tls:             settings.TLS,
+		metrics:         settings.Metrics,
+		shutdownTimeout: settings.ShutdownTimeout.Duration,
+	}
+}
+
+// Listens and serves until ctx is done, then stops accepting connections and waits up to the shutdown timeout
+// for requests in flight. Streams and WebSocket connections are closed since their contexts derive from ctx.
+// Returns nil after a graceful shutdown.
+func (server *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", server.http.Addr)
+	if err != nil {
+		return err
+	}
+	server.http.BaseContext = func(net.Listener) context.Context { return ctx }
+
+	scheme, wsScheme := "http", "ws"
+	if server.tls.CertFile != "" {
+		scheme, wsScheme = "https", "wss"
+	}
+	host := server.http.Addr
+	if strings.HasPrefix(host, ":") {
+		host = "localhost" + host
+	}
+
+	root := scheme + "://" + host
+	logger.Info("REST API now operating", "url", root+"/", "specification", root+"/openapi.json")
+	for _, endpoint := range []string{
+		root + "/{dataObject}",
+		root + "/{section}/snapshots",
+		root + "/{section}/at/{timestamp}",
+		root + "/{section}/{id}",
+		root + "/teams/{slug}",
+		root + "/matches/live/stream",
+		wsScheme + "://" + host + "/ws",
+		root + "/Ranking/{region}",
+		"POST " + root + "/graphql",
+		root + "/healthz",
+		root + "/readyz",
+	} {
+		logger.Debug("endpoint", "url", endpoint)
+	}
+	if server.metrics {
+		logger.Info("Prometheus metrics enabled", "url", root+"/metrics")
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		if server.tls.CertFile != "" {
+			served <- server.http.ServeTLS(listener, server.tls.CertFile, server.tls.KeyFile)
+		} else {
+			served <- server.http.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), server.shutdownTimeout)
+	defer cancel()
+	if err := server.http.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutting down REST API: %w", err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Tracks the outcome of the scrapes for /readyz.
+type ScrapeStatus struct {
+	mu          sync.Mutex
+	staleAfter  time.Duration
+	running     bool
+	lastStart   time.Time
+	lastFinish  time.Time
+	lastSuccess time.Time
+	failed      []string
+	degraded    []string
+}
+
+// Body of /readyz. The server is ready once a scrape has finished, for as long as the latest one got data for every
+// section and the last one that did isn't stale. Sections that failed only in part, e.g. a single ranking region, are
+// listed as degraded without making the server unready.
+type Readiness struct {
+	Ready       bool       `json:"ready"`
+	Scraping    bool       `json:"scraping"`
+	LastStart   *time.Time `json:"last_start,omitempty"`
+	LastFinish  *time.Time `json:"last_finish,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Failed      []string   `json:"failed,omitempty"`   // Sections the latest scrape got no data for.
+	Degraded    []string   `json:"degraded,omitempty"` // Sections the latest scrape got only part of, or "changes".
+	Reason      string     `json:"reason,omitempty"`   // Why the server isn't ready. Scrape errors are logged rather than exposed.
+}
+
+// Creates a status that counts the data as stale once no scrape has got every section for staleAfter, or never
+// when staleAfter is zero.
+func NewScrapeStatus(staleAfter time.Duration) *ScrapeStatus {
+	return &ScrapeStatus{staleAfter: staleAfter}
+}
+
+// Records the start of a scrape.
+func (status *ScrapeStatus) Start() {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	status.running = true
+	status.lastStart = time.Now().UTC()
+}
+
+// Records the end of a scrape: failed lists the sections it got no data for, and degraded those that finished with
+// errors all the same.
+func (status *ScrapeStatus) Finish(failed []string, degraded []string) {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+	status.running = false
+	status.lastFinish = time.Now().UTC()
+	status.failed = failed
+	status.degraded = degraded
+	if len(failed) == 0 {
+		status.lastSuccess = status.lastFinish
+	}
+}
+
+func (status *ScrapeStatus) Readiness() Readiness {
+	status.mu.Lock()
+	defer status.mu.Unlock()
+
+	optional := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		return &t
+	}
+	readiness := Readiness{
+		Scraping:    status.running,
+		LastStart:   optional(status.lastStart),
+		LastFinish:  optional(status.lastFinish),
+		LastSuccess: optional(status.lastSuccess),
+		Failed:      status.failed,
+		Degraded:    status.degraded,
+	}
+
+	switch {
+	case status.lastFinish.IsZero():
+		readiness.Reason = "no scrape has finished yet"
+	case len(status.failed) > 0:
+		readiness.Reason = "the latest scrape got no data for " + strings.Join(status.failed, ", ")
+	case status.staleAfter > 0 && time.Since(status.lastSuccess) > status.staleAfter:
+		readiness.Reason = "the data is stale"
+	default:
+		readiness.Ready = true
+	}
+	return readiness
+}
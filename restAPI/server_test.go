@@ -0,0 +1,63 @@
+package restAPI
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+)
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name     string
+		finish   func(status *ScrapeStatus)
+		ready    bool
+		reason   string
+		degraded []string
+	}{
+		{"before the first scrape", func(status *ScrapeStatus) {}, false, "no scrape has finished yet", nil},
+		{"complete scrape", func(status *ScrapeStatus) { status.Finish(nil, nil) }, true, "", nil},
+		{"partial failures", func(status *ScrapeStatus) {
+			status.Finish(nil, []string{"rankings", "changes"})
+		}, true, "", []string{"rankings", "changes"}},
+		{"section without data", func(status *ScrapeStatus) {
+			status.Finish(nil, nil)
+			status.Finish([]string{"threads"}, []string{"rankings"})
+		}, false, "the latest scrape got no data for threads", []string{"rankings"}},
+		{"recovered", func(status *ScrapeStatus) {
+			status.Finish([]string{"threads"}, nil)
+			status.Finish(nil, nil)
+		}, true, "", nil},
+		{"stale", func(status *ScrapeStatus) {
+			status.Finish(nil, nil)
+			status.lastSuccess = status.lastSuccess.Add(-2 * time.Hour)
+		}, false, "the data is stale", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status := NewScrapeStatus(time.Hour)
+			status.Start()
+			test.finish(status)
+
+			server := NewServer(config.Default().Server, NewAccessControl(config.Default().Server.Access), t.TempDir(), t.TempDir(), map[string]string{}, nil, nil, status)
+			response := httptest.NewRecorder()
+			server.http.Handler.ServeHTTP(response, httptest.NewRequest("GET", "/readyz", nil))
+
+			var readiness Readiness
+			if err := json.Unmarshal(response.Body.Bytes(), &readiness); err != nil {
+				t.Fatal(err)
+			}
+			wantCode := map[bool]int{true: http.StatusOK, false: http.StatusServiceUnavailable}[test.ready]
+			if response.Code != wantCode || readiness.Ready != test.ready || readiness.Reason != test.reason {
+				t.Errorf("got %d, ready %v and reason %q, want %d, %v and %q", response.Code, readiness.Ready, readiness.Reason, wantCode, test.ready, test.reason)
+			}
+			if !slices.Equal(readiness.Degraded, test.degraded) {
+				t.Errorf("degraded = %v, want %v", readiness.Degraded, test.degraded)
+			}
+		})
+	}
+}
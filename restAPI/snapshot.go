@@ -0,0 +1,181 @@
+package restAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/model"
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+// A single timestamped output file written by scrape.PageParser.
+type Snapshot struct {
+	model.Snapshot
+	path string
+}
+
+// Returned when a section has no snapshot matching the request.
+var ErrSnapshotNotFound = errors.New("snapshot not found")
+
+// Resolves section names (e.g. "threads") to the timestamped files stored in the output folder.
+// The folder is re-read on every lookup so snapshots from scheduled scrapes show up without a restart.
+type SnapshotIndex struct {
+	outputDir string
+	sections  map[string]string // Section name to output file prefix, e.g. "threads" to "outputThreads".
+
+	mu  sync.Mutex
+	ids map[string]map[string]map[int]bool // Item IDs of each snapshot read so far, by section and file name.
+}
+
+func NewSnapshotIndex(outputDir string, sections map[string]string) *SnapshotIndex {
+	return &SnapshotIndex{
+		outputDir: outputDir,
+		sections:  sections,
+		ids:       map[string]map[string]map[int]bool{},
+	}
+}
+
+// Returns the names of all indexed sections in alphabetical order.
+func (index *SnapshotIndex) Sections() []string {
+	var names []string
+	for name := range index.sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lists every snapshot of a section, newest first.
+func (index *SnapshotIndex) List(section string) ([]Snapshot, error) {
+	prefix, ok := index.sections[section]
+	if !ok {
+		return nil, ErrSnapshotNotFound
+	}
+
+	entries, err := os.ReadDir(index.outputDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Snapshot{}, nil
+		}
+		return nil, err
+	}
+
+	snapshots := []Snapshot{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix+"_") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		// Skip files that share the prefix but don't carry a scrape timestamp.
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix+"_"), ".json")
+		scrapedAt, err := time.ParseInLocation(scrape.TimestampLayout, timestamp, time.Local)
+		if err != nil {
+			continue
+		}
+
+		snapshots = append(snapshots, Snapshot{
+			Snapshot: model.Snapshot{
+				Section:   section,
+				Timestamp: timestamp,
+				ScrapedAt: scrapedAt,
+				File:      name,
+				URL:       "/" + section + "/at/" + timestamp,
+			},
+			path: filepath.Join(index.outputDir, name),
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].ScrapedAt.After(snapshots[j].ScrapedAt)
+	})
+
+	return snapshots, nil
+}
+
+// Returns the most recent snapshot of a section.
+func (index *SnapshotIndex) Latest(section string) (Snapshot, error) {
+	snapshots, err := index.List(section)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if len(snapshots) == 0 {
+		return Snapshot{}, ErrSnapshotNotFound
+	}
+	return snapshots[0], nil
+}
+
+// Returns the snapshot of a section taken at timestamp, formatted as scrape.TimestampLayout.
+func (index *SnapshotIndex) At(section string, timestamp string) (Snapshot, error) {
+	snapshots, err := index.List(section)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Timestamp == timestamp {
+			return snapshot, nil
+		}
+	}
+	return Snapshot{}, ErrSnapshotNotFound
+}
+
+// Reads the JSON contents of a snapshot.
+func (snapshot Snapshot) Read() ([]byte, error) {
+	return os.ReadFile(snapshot.path)
+}
+
+// Reports whether a snapshot has an item with the given "id". Snapshots don't change once written, so each one is
+// only read the first time, and lookups of IDs that were never scraped don't touch the disk again.
+func (index *SnapshotIndex) contains(snapshot Snapshot, id int) (bool, error) {
+	index.mu.Lock()
+	ids, ok := index.ids[snapshot.Section][snapshot.File]
+	index.mu.Unlock()
+	if ok {
+		return ids[id], nil
+	}
+
+	file, err := snapshot.Read()
+	if err != nil {
+		return false, err
+	}
+	var items []struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(file, &items); err != nil {
+		return false, err
+	}
+	ids = make(map[int]bool, len(items))
+	for _, item := range items {
+		ids[item.ID] = true
+	}
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	if index.ids[snapshot.Section] == nil {
+		index.ids[snapshot.Section] = map[string]map[int]bool{}
+	}
+	index.ids[snapshot.Section][snapshot.File] = ids
+	return ids[id], nil
+}
+
+// Drops the IDs of snapshots of a section that are no longer in list, e.g. after retention removed them.
+func (index *SnapshotIndex) forget(section string, list []Snapshot) {
+	listed := make(map[string]bool, len(list))
+	for _, snapshot := range list {
+		listed[snapshot.File] = true
+	}
+
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	for file := range index.ids[section] {
+		if !listed[file] {
+			delete(index.ids[section], file)
+		}
+	}
+}
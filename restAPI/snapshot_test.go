@@ -0,0 +1,105 @@
+package restAPI
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+func TestSnapshotIndex(t *testing.T) {
+	outputDir := t.TempDir()
+	for name, body := range map[string]string{
+		"outputThreads_2024-11-05_15-04-05.json": `[{"id": 9}]`,
+		"outputThreads_2024-11-06_15-04-05.json": `[{"id": 10}, {"id": 11}]`,
+		"outputThreads_latest.json":              `[]`, // No timestamp.
+		"outputThreads_2024-11-07_15-04-05.txt":  `[]`,
+		"outputMatches_2024-11-06_15-04-05.json": `[{"id": 101}]`,
+	} {
+		if err := os.WriteFile(filepath.Join(outputDir, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	index := NewSnapshotIndex(outputDir, map[string]string{"threads": "outputThreads", "matches": "outputMatches"})
+
+	if sections := index.Sections(); !slices.Equal(sections, []string{"matches", "threads"}) {
+		t.Errorf("Sections() = %v", sections)
+	}
+
+	list, err := index.List("threads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var timestamps []string
+	for _, snapshot := range list {
+		timestamps = append(timestamps, snapshot.Timestamp)
+	}
+	if !slices.Equal(timestamps, []string{"2024-11-06_15-04-05", "2024-11-05_15-04-05"}) {
+		t.Errorf("List(threads) = %v, want the timestamped files newest first", timestamps)
+	}
+	if list[0].URL != "/threads/at/2024-11-06_15-04-05" {
+		t.Errorf("URL = %q", list[0].URL)
+	}
+
+	if latest, err := index.Latest("threads"); err != nil || latest.Timestamp != "2024-11-06_15-04-05" {
+		t.Errorf("Latest(threads) = %v, %v", latest.Timestamp, err)
+	}
+	if snapshot, err := index.At("threads", "2024-11-05_15-04-05"); err != nil || snapshot.File != "outputThreads_2024-11-05_15-04-05.json" {
+		t.Errorf("At(threads, 2024-11-05_15-04-05) = %v, %v", snapshot.File, err)
+	}
+	_, missingTimestamp := index.At("threads", "2020-01-01_00-00-00")
+	_, unknownSection := index.Latest("rankings")
+	_, noFiles := NewSnapshotIndex(outputDir, map[string]string{"threads": "outputNothing"}).Latest("threads")
+	for name, err := range map[string]error{"missing timestamp": missingTimestamp, "unknown section": unknownSection, "no files": noFiles} {
+		if !errors.Is(err, ErrSnapshotNotFound) {
+			t.Errorf("%s: got %v, want ErrSnapshotNotFound", name, err)
+		}
+	}
+	if list, err := NewSnapshotIndex(filepath.Join(outputDir, "missing"), map[string]string{"threads": "outputThreads"}).List("threads"); err != nil || len(list) != 0 {
+		t.Errorf("List of a missing folder = %v, %v, want no snapshots", list, err)
+	}
+}
+
+func TestFindByID(t *testing.T) {
+	outputDir := t.TempDir()
+	write := func(name string, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(outputDir, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("outputThreads_2024-11-05_15-04-05.json", `[{"id": 9, "title": "Older"}, {"id": 10, "title": "Before the edit"}]`)
+	write("outputThreads_2024-11-06_15-04-05.json", `[{"id": 10, "title": "Newest"}]`)
+	index := NewSnapshotIndex(outputDir, map[string]string{"threads": "outputThreads"})
+	threadID := func(thread scrape.Thread) int { return thread.ID }
+
+	tests := []struct {
+		id    int
+		title string
+		err   error
+	}{
+		{10, "Newest", nil},
+		{9, "Older", nil},
+		{999, "", ErrEntityNotFound},
+	}
+	for _, test := range tests {
+		thread, err := findByID(index, "threads", test.id, threadID)
+		if !errors.Is(err, test.err) || thread.Title != test.title {
+			t.Errorf("findByID(%d) = %q, %v, want %q, %v", test.id, thread.Title, err, test.title, test.err)
+		}
+	}
+
+	// The IDs of snapshots removed since, e.g. by retention, are forgotten.
+	if err := os.Remove(filepath.Join(outputDir, "outputThreads_2024-11-05_15-04-05.json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := findByID(index, "threads", 9, threadID); !errors.Is(err, ErrEntityNotFound) {
+		t.Errorf("findByID(9) after removal returned %v, want ErrEntityNotFound", err)
+	}
+	if files := len(index.ids["threads"]); files != 1 {
+		t.Errorf("index holds the IDs of %d snapshots, want 1", files)
+	}
+}
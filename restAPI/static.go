@@ -0,0 +1,204 @@
+package restAPI
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+// Where each REST route lives in the static tree built by StaticTree. Keys are relative to the tree's root, and
+// links inside the static documents are the same keys with a leading slash, so a CDN serving the tree at its root
+// can be used in place of the server by requesting these paths instead.
+var StaticRoutes = map[string]string{
+	"GET /threads":                  "latest/threads.json",
+	"GET /matches":                  "latest/matches.json",
+	"GET /{section}/snapshots":      "{section}/snapshots.json",
+	"GET /{section}/at/{timestamp}": "{section}/at/{timestamp}.json",
+	"GET /threads/{id}":             "threads/{id}.json",
+	"GET /matches/{id}":             "matches/{id}.json",
+	"GET /teams/{slug}":             "teams/{slug}.json",
+	"GET /Ranking/{region}":         "latest/rankings/{region}.json",
+}
+
+// Root document of the static tree, index.json, linking to every other document. The regions with rankings and the
+// teams, which the REST API has no list for, are listed in latest/rankings/index.json and teams/index.json.
+type StaticIndex struct {
+	// Scrape time of the newest snapshot, so that publishing unchanged output gives an unchanged index.
+	GeneratedAt time.Time         `json:"generated_at"`
+	Latest      map[string]string `json:"latest"`    // Section, or "rankings", to its newest document.
+	Snapshots   map[string]string `json:"snapshots"` // Section to its list of snapshots.
+	Teams       string            `json:"teams"`
+	Routes      map[string]string `json:"routes"`
+}
+
+// An entry of latest/rankings/index.json or teams/index.json.
+type StaticLink struct {
+	Name string `json:"name"`
+	Slug string `json:"slug,omitempty"`
+	URL  string `json:"url"`
+}
+
+// Renders every document the REST API would serve for the current output as static JSON files, keyed by their
+// path in the tree (see StaticRoutes). Query parameters, the live stream, WebSockets and GraphQL have no static
+// equivalent. Threads and matches get a document for every ID in any snapshot, like their REST lookups.
+// Only the latest/ documents are rendered as of now. Snapshots, threads, matches and teams are rendered as of the
+// scrape they come from, with ages and countdowns at that time, so that they only change when rescraped.
+func StaticTree(outputDir string, rankingDir string, sections map[string]string) (map[string][]byte, error) {
+	snapshots := NewSnapshotIndex(outputDir, sections)
+	regions := NewRegionIndex(rankingDir)
+	tree := map[string][]byte{}
+	add := func(key string, value any) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		tree[key] = append(data, '\n')
+		return nil
+	}
+
+	index := StaticIndex{
+		Latest:    map[string]string{"rankings": "/latest/rankings/index.json"},
+		Snapshots: map[string]string{},
+		Teams:     "/teams/index.json",
+		Routes:    StaticRoutes,
+	}
+
+	for _, section := range snapshots.Sections() {
+		list, err := snapshots.List(section)
+		if err != nil {
+			return nil, err
+		}
+
+		// Each snapshot is rendered as of its scrape, and the newest again as of now for latest/. Links point into the tree.
+		for i, snapshot := range list {
+			file, err := snapshot.Read()
+			if err != nil {
+				return nil, err
+			}
+			key := section + "/at/" + snapshot.Timestamp + ".json"
+			if tree[key], err = displaySnapshot(section, file, snapshot.ScrapedAt); err != nil {
+				return nil, err
+			}
+			if i == 0 {
+				if tree["latest/"+section+".json"], err = displaySnapshot(section, file, displayTime()); err != nil {
+					return nil, err
+				}
+				index.Latest[section] = "/latest/" + section + ".json"
+				if snapshot.ScrapedAt.After(index.GeneratedAt) {
+					index.GeneratedAt = snapshot.ScrapedAt.UTC()
+				}
+			}
+			list[i].URL = "/" + key
+		}
+		if err := add(section+"/snapshots.json", list); err != nil {
+			return nil, err
+		}
+		index.Snapshots[section] = "/" + section + "/snapshots.json"
+	}
+
+	rankings, err := allRankings(regions)
+	if err != nil {
+		return nil, err
+	}
+	names, err := regions.Regions()
+	if err != nil {
+		return nil, err
+	}
+	regionLinks := []StaticLink{}
+	for _, name := range names {
+		file, err := regions.Read(name)
+		if err != nil {
+			return nil, err
+		}
+		key := "latest/rankings/" + name + ".json"
+		tree[key] = file
+		regionLinks = append(regionLinks, StaticLink{Name: name, URL: "/" + key})
+	}
+	if err := add("latest/rankings/index.json", regionLinks); err != nil {
+		return nil, err
+	}
+
+	if _, ok := sections["threads"]; ok {
+		if err := addEntities(snapshots, "threads", func(thread scrape.Thread) int { return thread.ID }, func(thread scrape.Thread, scrapedAt time.Time) any {
+			return thread.At(scrapedAt)
+		}, add); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := sections["matches"]; ok {
+		if err := addEntities(snapshots, "matches", func(match scrape.Match) int { return match.ID }, func(match scrape.Match, scrapedAt time.Time) any {
+			return matchDetail(match.At(scrapedAt, displayLocation), rankings)
+		}, add); err != nil {
+			return nil, err
+		}
+	}
+
+	// Teams are found through their rankings, as in findTeam, under their lower-cased slug since lookups ignore case.
+	matches, err := latestItems[scrape.Match](snapshots, "matches")
+	if err != nil {
+		return nil, err
+	}
+	if latest, err := snapshots.Latest("matches"); err == nil {
+		for i := range matches {
+			matches[i] = matches[i].At(latest.ScrapedAt, displayLocation)
+		}
+	}
+	teamLinks := []StaticLink{}
+	for _, ranking := range rankings {
+		slug := strings.ToLower(teamSlug(ranking.TeamURL))
+		key := "teams/" + slug + ".json"
+		if _, ok := tree[key]; ok || !validName(slug) {
+			continue
+		}
+		team := assembleTeam("", rankings, matches, func(other scrape.Ranking) bool { return strings.EqualFold(teamSlug(other.TeamURL), slug) })
+		if err := add(key, team); err != nil {
+			return nil, err
+		}
+		teamLinks = append(teamLinks, StaticLink{Name: team.Name, Slug: slug, URL: "/" + key})
+	}
+	if err := add("teams/index.json", teamLinks); err != nil {
+		return nil, err
+	}
+
+	if err := add("index.json", index); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// Adds a document for every item of a section, keeping the newest copy of items found in several snapshots.
+// document is given the scrape time of the snapshot the item was taken from.
+func addEntities[T any](snapshots *SnapshotIndex, section string, itemID func(T) int, document func(T, time.Time) any, add func(string, any) error) error {
+	list, err := snapshots.List(section)
+	if err != nil {
+		return err
+	}
+
+	seen := map[int]bool{}
+	for _, snapshot := range list {
+		file, err := snapshot.Read()
+		if err != nil {
+			return err
+		}
+
+		var items []T
+		if err := json.Unmarshal(file, &items); err != nil {
+			return err
+		}
+		for _, item := range items {
+			id := itemID(item)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			if err := add(section+"/"+strconv.Itoa(id)+".json", document(item, snapshot.ScrapedAt)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
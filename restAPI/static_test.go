@@ -0,0 +1,111 @@
+package restAPI
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+func TestStaticTreeRendersAsOfScrape(t *testing.T) {
+	outputDir := filepath.Join("testdata", "output")
+	sections := map[string]string{"threads": "outputThreads", "matches": "outputMatches"}
+	tree, err := StaticTree(outputDir, filepath.Join(outputDir, "ranking"), sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scrapedAt, err := time.ParseInLocation(scrape.TimestampLayout, "2024-11-06_15-04-05", time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decode := func(key string, document any) {
+		t.Helper()
+		if err := json.Unmarshal(tree[key], document); err != nil {
+			t.Fatalf("%s: %v", key, err)
+		}
+	}
+
+	var threads []scrape.Thread
+	decode("threads/at/2024-11-06_15-04-05.json", &threads)
+	var thread scrape.Thread
+	decode("threads/10.json", &thread)
+	want := threads[0]
+	want.AgeHours, want.FragsPerHour, want.CommentsPerHour = nil, nil, nil
+	want = want.At(scrapedAt)
+	for key, got := range map[string]scrape.Thread{"threads/at/2024-11-06_15-04-05.json": threads[0], "threads/10.json": thread} {
+		if got.AgeHours == nil || *got.AgeHours != *want.AgeHours || *got.FragsPerHour != *want.FragsPerHour {
+			t.Errorf("%s has age_hours %v and frags_per_hour %v, want %v and %v as of the scrape", key, value(got.AgeHours), value(got.FragsPerHour), *want.AgeHours, *want.FragsPerHour)
+		}
+	}
+
+	var latest []scrape.Thread
+	decode("latest/threads.json", &latest)
+	if latest[0].AgeHours == nil || *latest[0].AgeHours <= *want.AgeHours {
+		t.Errorf("latest/threads.json has age_hours %v, want it counted to now", value(latest[0].AgeHours))
+	}
+
+	var matches []scrape.Match
+	decode("matches/at/2024-11-06_15-04-05.json", &matches)
+	var match MatchDetail
+	decode("matches/102.json", &match)
+	scheduled := matches[1].ScheduledAt
+	wantETA := scrape.Match{ScheduledAt: scheduled}.At(scrapedAt, time.UTC).TimeUntilMatch
+	for key, got := range map[string]string{"matches/at/2024-11-06_15-04-05.json": matches[1].TimeUntilMatch, "matches/102.json": match.TimeUntilMatch} {
+		if got != wantETA {
+			t.Errorf("%s has time_until_match %q, want %q as of the scrape", key, got, wantETA)
+		}
+	}
+	var index StaticIndex
+	decode("index.json", &index)
+	if !index.GeneratedAt.Equal(scrapedAt) {
+		t.Errorf("index.json has generated_at %v, want the newest scrape %v", index.GeneratedAt, scrapedAt)
+	}
+
+	var latestMatches []scrape.Match
+	decode("latest/matches.json", &latestMatches)
+	if latestMatches[1].TimeUntilMatch != "0m" {
+		t.Errorf("latest/matches.json has time_until_match %q, want it counted down to now", latestMatches[1].TimeUntilMatch)
+	}
+}
+
+func TestStaticTreeLowerCasesTeamSlugs(t *testing.T) {
+	rankingDir := t.TempDir()
+	for name, body := range map[string]string{
+		"outputEuropeRankings.json":        `[{"rank": 1, "region": "Europe", "team_name": "FNATIC", "elo": 2000, "team_url": "https://www.vlr.gg/team/2593/FNATIC"}]`,
+		"outputNorth-AmericaRankings.json": `[{"rank": 9, "region": "North-America", "team_name": "FNATIC", "elo": 1500, "team_url": "https://www.vlr.gg/team/2593/fnatic"}]`,
+	} {
+		if err := os.WriteFile(filepath.Join(rankingDir, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tree, err := StaticTree(t.TempDir(), rankingDir, map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var team Team
+	if err := json.Unmarshal(tree["teams/fnatic.json"], &team); err != nil {
+		t.Fatalf("teams/fnatic.json: %v", err)
+	}
+	if len(team.Rankings) != 2 {
+		t.Errorf("teams/fnatic.json has %d rankings, want both regions", len(team.Rankings))
+	}
+	if _, ok := tree["teams/FNATIC.json"]; ok {
+		t.Error("the team is also published under its upper-case slug")
+	}
+	var links []StaticLink
+	if err := json.Unmarshal(tree["teams/index.json"], &links); err != nil || len(links) != 1 || links[0].Slug != "fnatic" {
+		t.Errorf("teams/index.json lists %+v (%v), want only fnatic", links, err)
+	}
+}
+
+// Returns what a pointer points to, or nil, for error messages.
+func value(pointer *float64) any {
+	if pointer == nil {
+		return nil
+	}
+	return *pointer
+}
@@ -0,0 +1,170 @@
+package restAPI
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"golang.org/x/net/websocket"
+)
+
+// Interval between server pings. Connections that send nothing for wsIdleTimeout are closed,
+// so clients should answer each ping with a pong.
+const (
+	wsPingInterval = 30 * time.Second
+	wsIdleTimeout  = 3 * wsPingInterval
+	wsWriteTimeout = 10 * time.Second
+)
+
+// Message sent by clients, e.g. {"action": "subscribe", "topics": ["match:12345", "rankings:Europe"]}.
+type wsRequest struct {
+	Action string   `json:"action"` // subscribe, unsubscribe, ping or pong
+	Topics []string `json:"topics,omitempty"`
+}
+
+// Message sent by the server. Type is subscribed, unsubscribed, event, ping, pong or error.
+type wsMessage struct {
+	Type    string       `json:"type"`
+	Topics  []string     `json:"topics,omitempty"`
+	Event   *ChangeEvent `json:"event,omitempty"`
+	Message string       `json:"message,omitempty"`
+}
+
+// Serves the WebSocket subscription API. Each connection subscribes to up to settings.MaxTopics topics and
+// receives the change events the hub publishes for them, along with periodic pings.
+func serveWebSocket(hub *ChangeHub, settings config.WebSocket) http.Handler {
+	handshake := func(_ *websocket.Config, r *http.Request) error {
+		if !allowedOrigin(r, settings.AllowedOrigins) {
+			return fmt.Errorf("origin %q is not allowed", r.Header.Get("Origin"))
+		}
+		return nil
+	}
+
+	return websocket.Server{Handshake: handshake, Handler: func(conn *websocket.Conn) {
+		defer conn.Close()
+
+		sub := hub.subscribe()
+		defer hub.unsubscribe(sub)
+
+		// Writes come from both the read loop (replies) and the event loop, so they are serialised.
+		var writeMu sync.Mutex
+		send := func(message wsMessage) error {
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			return websocket.JSON.Send(conn, message)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				conn.SetReadDeadline(time.Now().Add(wsIdleTimeout))
+				var request wsRequest
+				if err := websocket.JSON.Receive(conn, &request); err != nil {
+					return
+				}
+
+				var reply wsMessage
+				switch request.Action {
+				case "subscribe", "unsubscribe":
+					if invalid := invalidTopics(request.Topics); len(invalid) > 0 {
+						reply = wsMessage{Type: "error", Message: fmt.Sprintf("unknown topics %v", invalid)}
+						break
+					}
+					if request.Action == "subscribe" && len(union(hub.topics(sub), request.Topics)) > settings.MaxTopics {
+						reply = wsMessage{Type: "error", Message: fmt.Sprintf("a connection may subscribe to at most %d topics", settings.MaxTopics)}
+						break
+					}
+					hub.setTopics(sub, request.Topics, request.Action == "subscribe")
+					reply = wsMessage{Type: request.Action + "d", Topics: hub.topics(sub)}
+				case "ping":
+					reply = wsMessage{Type: "pong"}
+				case "pong":
+					continue
+				default:
+					reply = wsMessage{Type: "error", Message: fmt.Sprintf("unknown action %q", request.Action)}
+				}
+
+				if err := send(reply); err != nil {
+					return
+				}
+			}
+		}()
+
+		ping := time.NewTicker(wsPingInterval)
+		defer ping.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-conn.Request().Context().Done():
+				send(wsMessage{Type: "error", Message: "server is shutting down, reconnect and resubscribe"})
+				return
+			case event, open := <-sub.events:
+				// A closed channel means the client fell too far behind and was dropped by the hub.
+				if !open {
+					send(wsMessage{Type: "error", Message: "too slow to keep up, reconnect and resubscribe"})
+					return
+				}
+				if err := send(wsMessage{Type: "event", Event: &event}); err != nil {
+					return
+				}
+			case <-ping.C:
+				if err := send(wsMessage{Type: "ping"}); err != nil {
+					return
+				}
+			}
+		}
+	}}
+}
+
+// Returns the topics the hub can't publish to.
+func invalidTopics(topics []string) []string {
+	var invalid []string
+	for _, topic := range topics {
+		if !validTopic(topic) {
+			invalid = append(invalid, topic)
+		}
+	}
+	return invalid
+}
+
+// Returns the distinct topics of both lists.
+func union(current []string, added []string) []string {
+	topics := slices.Clone(current)
+	for _, topic := range added {
+		if !slices.Contains(topics, topic) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// Reports whether a connection may be opened from the Origin of r. Requests without one don't come from
+// a browser page and are let through, as are pages served by this host.
+func allowedOrigin(r *http.Request, allowed []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(parsed.Host, r.Host) {
+		return true
+	}
+	for _, candidate := range allowed {
+		if candidate == "*" || strings.EqualFold(strings.TrimSuffix(candidate, "/"), parsed.Scheme+"://"+parsed.Host) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package restAPI
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"golang.org/x/net/websocket"
+)
+
+func TestAllowedOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		allowed []string
+		want    bool
+	}{
+		{"no origin", "", nil, true},
+		{"same host", "http://api.example.com", nil, true},
+		{"foreign host", "https://evil.example", nil, false},
+		{"listed", "https://app.example.com", []string{"https://app.example.com"}, true},
+		{"listed with trailing slash", "https://app.example.com", []string{"https://app.example.com/"}, true},
+		{"other scheme", "http://app.example.com", []string{"https://app.example.com"}, false},
+		{"wildcard", "https://evil.example", []string{"*"}, true},
+		{"unparsable", "://", []string{"https://app.example.com"}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest("GET", "http://api.example.com/ws", nil)
+			if test.origin != "" {
+				request.Header.Set("Origin", test.origin)
+			}
+			if got := allowedOrigin(request, test.allowed); got != test.want {
+				t.Errorf("allowedOrigin(%q, %v) = %v, want %v", test.origin, test.allowed, got, test.want)
+			}
+		})
+	}
+}
+
+func TestWebSocketLimits(t *testing.T) {
+	outputDir := filepath.Join("testdata", "output")
+	hub := NewChangeHub(NewSnapshotIndex(outputDir, map[string]string{"threads": "outputThreads"}), NewRegionIndex(filepath.Join(outputDir, "ranking")))
+	server := httptest.NewServer(serveWebSocket(hub, config.WebSocket{AllowedOrigins: []string{"https://app.example.com"}, MaxTopics: 2}))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	if _, err := websocket.Dial(wsURL, "", "https://evil.example"); err == nil {
+		t.Error("connection from a foreign origin was accepted")
+	}
+
+	conn, err := websocket.Dial(wsURL, "", "https://app.example.com")
+	if err != nil {
+		t.Fatalf("connection from an allowed origin failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	steps := []struct {
+		request wsRequest
+		reply   string
+		topics  int
+	}{
+		{wsRequest{Action: "subscribe", Topics: []string{"thread:1", "thread:2"}}, "subscribed", 2},
+		{wsRequest{Action: "subscribe", Topics: []string{"thread:2"}}, "subscribed", 2},
+		{wsRequest{Action: "subscribe", Topics: []string{"thread:3"}}, "error", 0},
+		{wsRequest{Action: "unsubscribe", Topics: []string{"thread:1"}}, "unsubscribed", 1},
+		{wsRequest{Action: "subscribe", Topics: []string{"thread:3"}}, "subscribed", 2},
+	}
+	for _, step := range steps {
+		if err := websocket.JSON.Send(conn, step.request); err != nil {
+			t.Fatal(err)
+		}
+		var reply wsMessage
+		if err := websocket.JSON.Receive(conn, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply.Type != step.reply || len(reply.Topics) != step.topics {
+			t.Errorf("%s %v: got %s with topics %v, want %s with %d topics", step.request.Action, step.request.Topics, reply.Type, reply.Topics, step.reply, step.topics)
+		}
+	}
+}
+
+func TestChangeHubPublishesRemovedThreads(t *testing.T) {
+	outputDir := t.TempDir()
+	writeSnapshot := func(timestamp string, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(outputDir, "outputThreads_"+timestamp+".json"), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hub := NewChangeHub(NewSnapshotIndex(outputDir, map[string]string{"threads": "outputThreads"}), NewRegionIndex(filepath.Join(outputDir, "ranking")))
+
+	writeSnapshot("2024-11-06_15-00-00", `[{"id": 1, "title": "Stays"}, {"id": 2, "title": "Drops off"}]`)
+	if err := hub.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	sub := hub.subscribe()
+	defer hub.unsubscribe(sub)
+	hub.setTopics(sub, []string{"thread:1", "thread:2"}, true)
+
+	writeSnapshot("2024-11-06_16-00-00", `[{"id": 1, "title": "Stays"}]`)
+	if err := hub.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-sub.events:
+		if event.Topic != "thread:2" || event.Type != ChangeRemoved {
+			t.Errorf("got %s %s, want thread:2 %s", event.Topic, event.Type, ChangeRemoved)
+		}
+	default:
+		t.Fatal("no event for the thread that is no longer listed")
+	}
+	select {
+	case event := <-sub.events:
+		t.Errorf("unexpected %s %s event", event.Topic, event.Type)
+	default:
+	}
+}
+
+func TestChangeHubIgnoresScrapeRelativeFields(t *testing.T) {
+	outputDir := t.TempDir()
+	writeSnapshot := func(name string, body string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(outputDir, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sections := map[string]string{"threads": "outputThreads", "matches": "outputMatches"}
+	hub := NewChangeHub(NewSnapshotIndex(outputDir, sections), NewRegionIndex(filepath.Join(outputDir, "ranking")))
+
+	writeSnapshot("outputThreads_2024-11-06_15-00-00.json", `[{"id": 1, "title": "Same", "date_published_ago": "2h", "published_at": "2024-11-06T13:00:00Z"}]`)
+	writeSnapshot("outputMatches_2024-11-06_15-00-00.json", `[{"id": 5, "team1": "FNATIC", "time_until_match": "1d 2h"}, {"id": 6, "team1": "Sentinels", "time_until_match": "5m"}]`)
+	if err := hub.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	sub := hub.subscribe()
+	defer hub.unsubscribe(sub)
+	hub.setTopics(sub, []string{"thread:1", "match:5", "match:6"}, true)
+
+	// Only the countdowns, ages and estimated publish times moved, apart from match 6 going live.
+	writeSnapshot("outputThreads_2024-11-06_16-00-00.json", `[{"id": 1, "title": "Same", "date_published_ago": "3h", "published_at": "2024-11-06T13:00:00Z"}]`)
+	writeSnapshot("outputMatches_2024-11-06_16-00-00.json", `[{"id": 5, "team1": "FNATIC", "time_until_match": "1d 1h"}, {"id": 6, "team1": "Sentinels", "time_until_match": "Live"}]`)
+	if err := hub.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-sub.events:
+		if event.Topic != "match:6" || event.Type != ChangeUpdated {
+			t.Errorf("got %s %s, want match:6 %s", event.Topic, event.Type, ChangeUpdated)
+		}
+	default:
+		t.Fatal("no event for the match that went live")
+	}
+	select {
+	case event := <-sub.events:
+		t.Errorf("unexpected %s %s event", event.Topic, event.Type)
+	default:
+	}
+
+	// A scrape of unchanged data publishes nothing.
+	writeSnapshot("outputThreads_2024-11-06_17-00-00.json", `[{"id": 1, "title": "Same", "date_published_ago": "4h", "published_at": "2024-11-06T14:00:00Z"}]`)
+	writeSnapshot("outputMatches_2024-11-06_17-00-00.json", `[{"id": 5, "team1": "FNATIC", "time_until_match": "1d 0h"}, {"id": 6, "team1": "Sentinels", "time_until_match": "Live"}]`)
+	if err := hub.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-sub.events:
+		t.Errorf("unexpected %s %s event", event.Topic, event.Type)
+	default:
+	}
+}
@@ -0,0 +1,126 @@
+package retention
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+// One snapshot in an archive. In ndjson.gz archives each is a line of JSON with the snapshot inlined as data; in
+// tar.gz archives each is a file named Name and dated Timestamp.
+type archiveEntry struct {
+	Name      string          `json:"name"`
+	Section   string          `json:"section"`
+	Timestamp string          `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Adds entry to entries, replacing an entry of the same name left by an interrupted run.
+func addEntry(entries []archiveEntry, entry archiveEntry) []archiveEntry {
+	for i := range entries {
+		if entries[i].Name == entry.Name {
+			entries[i] = entry
+			return entries
+		}
+	}
+	return append(entries, entry)
+}
+
+func readArchive(format string, data []byte) ([]archiveEntry, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var entries []archiveEntry
+	switch format {
+	case config.ArchiveNDJSON:
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(nil, 64<<20)
+		for scanner.Scan() {
+			var entry archiveEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+		return entries, scanner.Err()
+
+	case config.ArchiveTar:
+		archive := tar.NewReader(reader)
+		for {
+			header, err := archive.Next()
+			if err == io.EOF {
+				return entries, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(archive)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, archiveEntry{Name: header.Name, Timestamp: header.ModTime.Format(scrape.TimestampLayout), Data: data})
+		}
+	}
+	return nil, fmt.Errorf("unknown archive format %q", format)
+}
+
+// Writes the entries, oldest first.
+func writeArchive(format string, entries []archiveEntry) ([]byte, error) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Timestamp < entries[j].Timestamp })
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	switch format {
+	case config.ArchiveNDJSON:
+		for _, entry := range entries {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, entry.Data); err != nil {
+				return nil, fmt.Errorf("%s: %w", entry.Name, err)
+			}
+			entry.Data = compact.Bytes()
+			line, err := json.Marshal(entry)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := writer.Write(append(line, '\n')); err != nil {
+				return nil, err
+			}
+		}
+
+	case config.ArchiveTar:
+		archive := tar.NewWriter(writer)
+		for _, entry := range entries {
+			modTime, _ := time.ParseInLocation(scrape.TimestampLayout, entry.Timestamp, time.Local)
+			header := &tar.Header{Name: entry.Name, Mode: 0644, Size: int64(len(entry.Data)), ModTime: modTime}
+			if err := archive.WriteHeader(header); err != nil {
+				return nil, err
+			}
+			if _, err := archive.Write(entry.Data); err != nil {
+				return nil, err
+			}
+		}
+		if err := archive.Close(); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown archive format %q", format)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
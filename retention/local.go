@@ -0,0 +1,66 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/mrovengerdev/vlrscrape/sink"
+)
+
+// The snapshots in the output folder, with archives kept in its archive folder.
+type Local struct {
+	dir      string
+	sections map[string]string
+	files    *sink.Local
+}
+
+// sections maps each section to its output file prefix, e.g. "threads" to "outputThreads".
+func NewLocal(dir string, sections map[string]string) *Local {
+	return &Local{dir: dir, sections: sections, files: sink.NewLocal(dir)}
+}
+
+func (local *Local) Name() string {
+	return "local " + local.dir
+}
+
+func (local *Local) Snapshots(ctx context.Context) ([]Snapshot, error) {
+	entries, err := os.ReadDir(local.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if section, scrapedAt, ok := ParseName(entry.Name(), local.sections); ok {
+			snapshots = append(snapshots, Snapshot{Name: entry.Name(), Section: section, ScrapedAt: scrapedAt})
+		}
+	}
+	return snapshots, nil
+}
+
+func (local *Local) Read(ctx context.Context, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(local.dir, filepath.FromSlash(name)))
+}
+
+// Writes through sink.Local, so an archive is never left half-written.
+func (local *Local) Write(ctx context.Context, name string, data []byte) error {
+	return local.files.Write(ctx, name, data)
+}
+
+func (local *Local) Delete(ctx context.Context, names []string) error {
+	var errs []error
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(local.dir, filepath.FromSlash(name))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
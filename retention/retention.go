@@ -0,0 +1,197 @@
+// Package retention thins out old thread and match snapshots following config.Retention, compacting the ones it
+// removes into per-day archives. It works the same on any Store, such as the output folder or an S3 bucket.
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/logging"
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+// Where archives are kept in every store. -upload leaves it alone, as each store writes its own archives.
+const ArchivePrefix = "archive/"
+
+// A snapshot file found in a Store.
+type Snapshot struct {
+	Name      string // Name in the store, e.g. outputThreads_2024-11-07_15-04-05.json or an object key.
+	Section   string
+	ScrapedAt time.Time
+}
+
+// Where snapshots and their archives are kept.
+type Store interface {
+	// Short description used in logs, e.g. "local output".
+	Name() string
+	// Lists the thread and match snapshots in the store.
+	Snapshots(ctx context.Context) ([]Snapshot, error)
+	// Returns an error wrapping fs.ErrNotExist when nothing is stored under name.
+	Read(ctx context.Context, name string) ([]byte, error)
+	Write(ctx context.Context, name string, data []byte) error
+	Delete(ctx context.Context, names []string) error
+}
+
+// What Apply did, or in a dry run would do, to a store.
+type Report struct {
+	Kept      int      `json:"kept"`
+	Compacted []string `json:"compacted,omitempty"` // Snapshots removed from the store.
+	Archives  []string `json:"archives,omitempty"`  // Archives they were added to.
+}
+
+// Recognises the snapshot files written by scrape.PageParser, named <prefix>_<timestamp>.json at the top of the
+// output folder. sections maps each section to its prefix, e.g. "threads" to "outputThreads".
+func ParseName(name string, sections map[string]string) (string, time.Time, bool) {
+	// The prefix may contain underscores itself, so the timestamp is cut off by its length.
+	stem, ok := strings.CutSuffix(name, ".json")
+	split := len(stem) - len(scrape.TimestampLayout) - 1
+	if !ok || strings.Contains(name, "/") || split < 1 || stem[split] != '_' {
+		return "", time.Time{}, false
+	}
+	for section, prefix := range sections {
+		if stem[:split] != prefix {
+			continue
+		}
+		scrapedAt, err := time.ParseInLocation(scrape.TimestampLayout, stem[split+1:], time.Local)
+		if err != nil {
+			return "", time.Time{}, false
+		}
+		return section, scrapedAt, true
+	}
+	return "", time.Time{}, false
+}
+
+// Returns the name of the archive holding a section's compacted snapshots of a day, e.g. archive/threads/2024-11-07.ndjson.gz.
+func ArchiveName(section string, day time.Time, format string) string {
+	return path.Join(ArchivePrefix, section, day.Format("2006-01-02")+"."+format)
+}
+
+// Compacts every snapshot in store that policy no longer keeps at now into the archive of its section and day, then
+// deletes it. A day that fails is logged and left for the next run; the returned error joins every failure.
+// With dryRun the store isn't changed.
+func Apply(ctx context.Context, logger *slog.Logger, store Store, policy config.Retention, now time.Time, dryRun bool) (Report, error) {
+	logger = logger.With("store", store.Name())
+
+	snapshots, err := store.Snapshots(ctx)
+	if err != nil {
+		return Report{}, fmt.Errorf("listing snapshots: %w", err)
+	}
+	keep, compact := plan(snapshots, policy, now)
+	report := Report{Kept: len(keep)}
+
+	// One archive per section and day.
+	days := map[string][]Snapshot{}
+	for _, snapshot := range compact {
+		name := ArchiveName(snapshot.Section, snapshot.ScrapedAt, policy.Archive)
+		days[name] = append(days[name], snapshot)
+	}
+	var names []string
+	for name := range days {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		var removed []string
+		for _, snapshot := range days[name] {
+			removed = append(removed, snapshot.Name)
+		}
+		dayLogger := logger.With("archive", name, "snapshots", len(removed))
+		if dryRun {
+			dayLogger.Info("would compact snapshots")
+		} else {
+			if err := compactDay(ctx, store, policy.Archive, name, days[name]); err != nil {
+				dayLogger.Error("compacting snapshots failed", logging.Err(err))
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			dayLogger.Info("compacted snapshots")
+		}
+		report.Compacted = append(report.Compacted, removed...)
+		if policy.Archive != config.ArchiveNone {
+			report.Archives = append(report.Archives, name)
+		}
+	}
+
+	logger.Info("retention applied", "dry_run", dryRun, "kept", report.Kept, "compacted", len(report.Compacted), "archives", len(report.Archives))
+	return report, errors.Join(errs...)
+}
+
+// Splits snapshots into those policy keeps at now and those to compact. Going from newest to oldest, a snapshot is
+// kept when it's younger than KeepAll, the newest of its hour and younger than KeepHourly, or the newest of its day
+// and younger than KeepDaily (always when KeepDaily is 0).
+func plan(snapshots []Snapshot, policy config.Retention, now time.Time) (keep []Snapshot, compact []Snapshot) {
+	sorted := append([]Snapshot(nil), snapshots...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ScrapedAt.After(sorted[j].ScrapedAt) })
+
+	hours := map[string]bool{}
+	days := map[string]bool{}
+	for _, snapshot := range sorted {
+		age := now.Sub(snapshot.ScrapedAt)
+		hour := snapshot.Section + snapshot.ScrapedAt.Format(" 2006-01-02 15")
+		day := snapshot.Section + snapshot.ScrapedAt.Format(" 2006-01-02")
+
+		kept := age < policy.KeepAll.Duration ||
+			(age < policy.KeepHourly.Duration && !hours[hour]) ||
+			((policy.KeepDaily.Duration == 0 || age < policy.KeepDaily.Duration) && !days[day])
+		if kept {
+			keep = append(keep, snapshot)
+			hours[hour], days[day] = true, true
+		} else {
+			compact = append(compact, snapshot)
+		}
+	}
+	return keep, compact
+}
+
+// Adds the snapshots to the archive, which may already hold earlier ones of the same day, and deletes them.
+// The archive is written first so a failure never loses a snapshot.
+func compactDay(ctx context.Context, store Store, format string, name string, snapshots []Snapshot) error {
+	var names []string
+	for _, snapshot := range snapshots {
+		names = append(names, snapshot.Name)
+	}
+	if format == config.ArchiveNone {
+		return store.Delete(ctx, names)
+	}
+
+	var entries []archiveEntry
+	existing, err := store.Read(ctx, name)
+	if err == nil {
+		entries, err = readArchive(format, existing)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("reading archive: %w", err)
+	}
+
+	for _, snapshot := range snapshots {
+		data, err := store.Read(ctx, snapshot.Name)
+		if err != nil {
+			return err
+		}
+		entries = addEntry(entries, archiveEntry{
+			Name:      path.Base(snapshot.Name),
+			Section:   snapshot.Section,
+			Timestamp: snapshot.ScrapedAt.Format(scrape.TimestampLayout),
+			Data:      data,
+		})
+	}
+
+	archive, err := writeArchive(format, entries)
+	if err != nil {
+		return err
+	}
+	if err := store.Write(ctx, name, archive); err != nil {
+		return fmt.Errorf("writing archive: %w", err)
+	}
+	return store.Delete(ctx, names)
+}
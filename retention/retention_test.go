@@ -0,0 +1,95 @@
+package retention
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+)
+
+func TestPlan(t *testing.T) {
+	now := time.Date(2024, 11, 10, 12, 0, 0, 0, time.UTC)
+	snapshot := func(section string, scrapedAt string) Snapshot {
+		at, err := time.Parse("2006-01-02 15:04", scrapedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return Snapshot{Name: section + " " + scrapedAt, Section: section, ScrapedAt: at}
+	}
+	snapshots := []Snapshot{
+		snapshot("threads", "2024-11-01 10:00"),
+		snapshot("threads", "2024-11-08 08:00"),
+		snapshot("threads", "2024-11-08 10:00"),
+		snapshot("matches", "2024-11-08 09:00"),
+		snapshot("threads", "2024-11-09 03:30"),
+		snapshot("threads", "2024-11-09 03:10"),
+		snapshot("threads", "2024-11-10 05:10"),
+		snapshot("threads", "2024-11-10 05:40"),
+		snapshot("threads", "2024-11-10 07:00"),
+		snapshot("threads", "2024-11-10 07:20"),
+		snapshot("threads", "2024-11-10 11:30"),
+	}
+	hours := func(n int) config.Duration { return config.Duration{Duration: time.Duration(n) * time.Hour} }
+
+	tests := []struct {
+		name    string
+		policy  config.Retention
+		keep    []string
+		compact []string
+	}{
+		{
+			name:   "all, hourly and daily",
+			policy: config.Retention{KeepAll: hours(6), KeepHourly: hours(48), KeepDaily: hours(7 * 24)},
+			keep: []string{
+				"threads 2024-11-10 11:30", "threads 2024-11-10 07:20", "threads 2024-11-10 07:00", // Younger than KeepAll.
+				"threads 2024-11-10 05:40", "threads 2024-11-09 03:30", // Newest of their hour.
+				"threads 2024-11-08 10:00", "matches 2024-11-08 09:00", // Newest of their day, per section.
+			},
+			compact: []string{"threads 2024-11-10 05:10", "threads 2024-11-09 03:10", "threads 2024-11-08 08:00", "threads 2024-11-01 10:00"},
+		},
+		{
+			name:   "daily forever",
+			policy: config.Retention{KeepAll: hours(6), KeepHourly: hours(48)},
+			keep: []string{
+				"threads 2024-11-10 11:30", "threads 2024-11-10 07:20", "threads 2024-11-10 07:00",
+				"threads 2024-11-10 05:40", "threads 2024-11-09 03:30",
+				"threads 2024-11-08 10:00", "matches 2024-11-08 09:00", "threads 2024-11-01 10:00",
+			},
+			compact: []string{"threads 2024-11-10 05:10", "threads 2024-11-09 03:10", "threads 2024-11-08 08:00"},
+		},
+		{
+			name:    "newest of each day only",
+			policy:  config.Retention{KeepDaily: hours(7 * 24)},
+			keep:    []string{"threads 2024-11-10 11:30", "threads 2024-11-09 03:30", "threads 2024-11-08 10:00", "matches 2024-11-08 09:00"},
+			compact: []string{"threads 2024-11-10 07:20", "threads 2024-11-10 07:00", "threads 2024-11-10 05:40", "threads 2024-11-10 05:10", "threads 2024-11-09 03:10", "threads 2024-11-08 08:00", "threads 2024-11-01 10:00"},
+		},
+		{
+			name:   "keep everything recent",
+			policy: config.Retention{KeepAll: hours(30 * 24)},
+			keep: []string{
+				"threads 2024-11-10 11:30", "threads 2024-11-10 07:20", "threads 2024-11-10 07:00", "threads 2024-11-10 05:40",
+				"threads 2024-11-10 05:10", "threads 2024-11-09 03:30", "threads 2024-11-09 03:10", "threads 2024-11-08 10:00",
+				"matches 2024-11-08 09:00", "threads 2024-11-08 08:00", "threads 2024-11-01 10:00",
+			},
+		},
+	}
+	names := func(snapshots []Snapshot) []string {
+		var names []string
+		for _, snapshot := range snapshots {
+			names = append(names, snapshot.Name)
+		}
+		return names
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keep, compact := plan(snapshots, test.policy, now)
+			if got := names(keep); !slices.Equal(got, test.keep) {
+				t.Errorf("keep = %v, want %v", got, test.keep)
+			}
+			if got := names(compact); !slices.Equal(got, test.compact) {
+				t.Errorf("compact = %v, want %v", got, test.compact)
+			}
+		})
+	}
+}
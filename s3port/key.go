@@ -0,0 +1,185 @@
+package s3port
+
+import (
+	"bytes"
+	"compress/gzip"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/mrovengerdev/vlrscrape/columnar"
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/retention"
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+// Turns output file names into object keys with settings.KeyTemplate, e.g. {section}/dt={YYYY-MM-DD}/{section}_{region}_{timestamp}.json,
+// and snapshot keys back into their section and scrape time. With settings.Format parquet, the files of a section are
+// converted to Parquet and their keys end in .parquet instead of .json.
+type keyLayout struct {
+	template   string
+	parquet    bool
+	pattern    *regexp.Regexp    // Matches the keys template produces.
+	sections   map[string]string // Section name to output file prefix, e.g. "threads" to "outputThreads".
+	rankingDir string
+}
+
+// Patterns matching what each placeholder of a key template expands to.
+var placeholderPatterns = map[string]string{
+	"{name}":       `.+`,
+	"{section}":    `[a-z]+`,
+	"{region}":     `[a-z0-9-]*`,
+	"{timestamp}":  `\d{4}-\d{2}-\d{2}_\d{2}-\d{2}-\d{2}`,
+	"{YYYY-MM-DD}": `\d{4}-\d{2}-\d{2}`,
+	"{YYYY}":       `\d{4}`,
+	"{MM}":         `\d{2}`,
+	"{DD}":         `\d{2}`,
+}
+
+var placeholderRegexp = regexp.MustCompile(`\{[^}]*\}`)
+
+func newKeyLayout(settings config.S3, sections config.Sections) keyLayout {
+	// The first {name}, {section} and {timestamp} are captured for parse.
+	template := settings.KeyTemplate
+	pattern, last := "^", 0
+	captured := map[string]bool{}
+	for _, loc := range placeholderRegexp.FindAllStringIndex(template, -1) {
+		placeholder := template[loc[0]:loc[1]]
+		name := strings.Trim(placeholder, "{}")
+		pattern += regexp.QuoteMeta(template[last:loc[0]])
+		if (name == "name" || name == "section" || name == "timestamp") && !captured[name] {
+			pattern += "(?P<" + name + ">" + placeholderPatterns[placeholder] + ")"
+			captured[name] = true
+		} else {
+			pattern += "(?:" + placeholderPatterns[placeholder] + ")"
+		}
+		last = loc[1]
+	}
+	pattern += regexp.QuoteMeta(template[last:]) + "$"
+
+	return keyLayout{
+		template:   settings.KeyTemplate,
+		parquet:    settings.Format == config.S3FormatParquet,
+		pattern:    regexp.MustCompile(pattern),
+		sections:   map[string]string{"threads": sections.Threads.Output, "matches": sections.Matches.Output},
+		rankingDir: sections.Rankings.Output,
+	}
+}
+
+// Returns the key of the output file name. The scrape time comes from the timestamp in the name; rankings don't
+// have one, so fallback is used instead. Files that belong to no section keep their name as key.
+func (layout keyLayout) key(name string, fallback time.Time) string {
+	file, ok := columnar.Locate(name, fallback, layout.sections, layout.rankingDir)
+	if !ok {
+		return name
+	}
+
+	key := strings.NewReplacer(
+		"{name}", name,
+		"{section}", file.Section,
+		"{region}", file.Region,
+		"{timestamp}", file.ScrapedAt.Format(scrape.TimestampLayout),
+		"{YYYY-MM-DD}", file.ScrapedAt.Format("2006-01-02"),
+		"{YYYY}", file.ScrapedAt.Format("2006"),
+		"{MM}", file.ScrapedAt.Format("01"),
+		"{DD}", file.ScrapedAt.Format("02"),
+	).Replace(layout.template)
+	if layout.parquet {
+		key = strings.TrimSuffix(key, ".json") + ".parquet"
+	}
+	return key
+}
+
+// Returns the content stored for the output file name, converted to Parquet when the layout asks for it.
+func (layout keyLayout) convert(name string, fallback time.Time, data []byte) ([]byte, error) {
+	if !layout.parquet {
+		return data, nil
+	}
+	file, ok := columnar.Locate(name, fallback, layout.sections, layout.rankingDir)
+	if !ok {
+		return data, nil
+	}
+	return columnar.Encode(file.Section, data, file.ScrapedAt)
+}
+
+// Returns the section and scrape time of a thread or match snapshot key produced by key.
+func (layout keyLayout) parse(key string) (string, time.Time, bool) {
+	match := layout.pattern.FindStringSubmatch(key)
+	if match == nil {
+		return "", time.Time{}, false
+	}
+	if i := layout.pattern.SubexpIndex("name"); i >= 0 {
+		return retention.ParseName(match[i], layout.sections)
+	}
+
+	sectionIndex, timestampIndex := layout.pattern.SubexpIndex("section"), layout.pattern.SubexpIndex("timestamp")
+	if sectionIndex < 0 || timestampIndex < 0 {
+		return "", time.Time{}, false
+	}
+	section := match[sectionIndex]
+	if _, ok := layout.sections[section]; !ok {
+		return "", time.Time{}, false
+	}
+	scrapedAt, err := time.ParseInLocation(scrape.TimestampLayout, match[timestampIndex], time.Local)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return section, scrapedAt, true
+}
+
+// Returns the settings an object is stored with. Parquet files, which are compressed already, are never gzipped and
+// have their own content type.
+func objectSettings(settings config.S3, key string) config.S3 {
+	if settings.Format == config.S3FormatParquet && strings.HasSuffix(key, ".parquet") {
+		settings.Gzip = false
+		settings.ContentType = columnar.ContentType
+	}
+	return settings
+}
+
+// Returns the body stored for data, gzipped when settings.Gzip is set.
+func encodeBody(settings config.S3, data []byte) ([]byte, error) {
+	if !settings.Gzip {
+		return data, nil
+	}
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// Builds the PutObject request for an encoded body, with the ACL, storage class, encryption and content headers
+// from settings. Empty settings leave the bucket's defaults in place.
+func newPutObjectInput(settings config.S3, key string, body []byte, sha256 string) *s3.PutObjectInput {
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(settings.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(settings.ContentType),
+		Metadata:    map[string]string{sha256Metadata: sha256},
+	}
+	if settings.Gzip {
+		input.ContentEncoding = aws.String("gzip")
+	}
+	if settings.ACL != "" {
+		input.ACL = types.ObjectCannedACL(settings.ACL)
+	}
+	if settings.StorageClass != "" {
+		input.StorageClass = types.StorageClass(settings.StorageClass)
+	}
+	if settings.ServerSideEncryption != "" {
+		input.ServerSideEncryption = types.ServerSideEncryption(settings.ServerSideEncryption)
+	}
+	if settings.KMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(settings.KMSKeyID)
+	}
+	return input
+}
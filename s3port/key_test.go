@@ -0,0 +1,70 @@
+package s3port
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+)
+
+func TestKeyLayoutRoundTrip(t *testing.T) {
+	sections := config.Default().Sections
+	scrapedAt := time.Date(2024, 11, 6, 15, 4, 5, 0, time.Local)
+	fallback := time.Date(2024, 11, 7, 8, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		template string
+		name     string
+		key      string
+		section  string // Empty when parse shouldn't recognise the key as a snapshot.
+	}{
+		{"{name}", "outputThreads_2024-11-06_15-04-05.json", "outputThreads_2024-11-06_15-04-05.json", "threads"},
+		{"{name}", "ranking/outputEuropeRankings.json", "ranking/outputEuropeRankings.json", ""},
+		{"raw/{name}", "outputMatches_2024-11-06_15-04-05.json", "raw/outputMatches_2024-11-06_15-04-05.json", "matches"},
+		{"{section}/dt={YYYY-MM-DD}/{section}_{timestamp}.json", "outputThreads_2024-11-06_15-04-05.json", "threads/dt=2024-11-06/threads_2024-11-06_15-04-05.json", "threads"},
+		{"{section}/dt={YYYY-MM-DD}/{section}_{region}_{timestamp}.json", "outputThreads_2024-11-06_15-04-05.json", "threads/dt=2024-11-06/threads__2024-11-06_15-04-05.json", "threads"},
+		{"{section}/dt={YYYY-MM-DD}/{section}_{region}_{timestamp}.json", "ranking/outputNorth-AmericaRankings.json", "rankings/dt=2024-11-07/rankings_north-america_2024-11-07_08-00-00.json", ""},
+		{"{section}/{YYYY}/{MM}/{DD}/{timestamp}.json", "outputMatches_2024-11-06_15-04-05.json", "matches/2024/11/06/2024-11-06_15-04-05.json", "matches"},
+		{"{section}/{region}/{timestamp}.json", "ranking/outputEuropeRankings.json", "rankings/europe/2024-11-07_08-00-00.json", ""},
+		{"{section}/{timestamp}.json", "notes.txt", "notes.txt", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.template+" "+test.name, func(t *testing.T) {
+			layout := newKeyLayout(config.S3{KeyTemplate: test.template, Format: config.S3FormatJSON}, sections)
+
+			key := layout.key(test.name, fallback)
+			if key != test.key {
+				t.Fatalf("key(%q) = %q, want %q", test.name, key, test.key)
+			}
+
+			section, parsedAt, ok := layout.parse(key)
+			if test.section == "" {
+				if ok {
+					t.Errorf("parse(%q) recognised a %s snapshot, want none", key, section)
+				}
+				return
+			}
+			if !ok || section != test.section || !parsedAt.Equal(scrapedAt) {
+				t.Errorf("parse(%q) = %q, %v, %v, want %q, %v, true", key, section, parsedAt, ok, test.section, scrapedAt)
+			}
+		})
+	}
+}
+
+func TestKeyLayoutParquet(t *testing.T) {
+	layout := newKeyLayout(config.S3{KeyTemplate: "{section}/dt={YYYY-MM-DD}/{section}_{region}_{timestamp}.json", Format: config.S3FormatParquet}, config.Default().Sections)
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"outputThreads_2024-11-06_15-04-05.json", "threads/dt=2024-11-06/threads__2024-11-06_15-04-05.parquet"},
+		{"ranking/outputEuropeRankings.json", "rankings/dt=2024-11-07/rankings_europe_2024-11-07_08-00-00.parquet"},
+		{"ranking/outputNorth-AmericaRankings.json", "rankings/dt=2024-11-07/rankings_north-america_2024-11-07_08-00-00.parquet"},
+	}
+	for _, test := range tests {
+		if key := layout.key(test.name, time.Date(2024, 11, 7, 8, 0, 0, 0, time.Local)); key != test.key {
+			t.Errorf("key(%q) = %q, want %q", test.name, key, test.key)
+		}
+	}
+}
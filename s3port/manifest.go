@@ -0,0 +1,139 @@
+package s3port
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Prefix of the per-run manifests. Upload never compares, overwrites or prunes anything under it.
+const manifestPrefix = "manifests/"
+
+// Metadata key holding the hex SHA-256 of an object's content, for objects whose ETag isn't an MD5.
+const sha256Metadata = "sha256"
+
+// What an Upload or Publish did to the bucket. Upload also writes it to manifests/<timestamp>/manifest.json.
+type Manifest struct {
+	Run       string          `json:"run"`
+	Bucket    string          `json:"bucket"`
+	DryRun    bool            `json:"dry_run,omitempty"`
+	Uploaded  []ManifestEntry `json:"uploaded"`
+	Unchanged []ManifestEntry `json:"unchanged"`         // Already in the bucket with the same content.
+	Skipped   []string        `json:"skipped,omitempty"` // Files under the reserved manifests/ prefix.
+	Failed    []ManifestEntry `json:"failed,omitempty"`  // Still failing after every attempt, and retried on the next run.
+	Pruned    []string        `json:"pruned,omitempty"`  // Keys deleted because their local file is gone.
+}
+
+type ManifestEntry struct {
+	File     string `json:"file"` // Relative to the output folder.
+	Key      string `json:"key"`
+	Size     int64  `json:"size,omitempty"`
+	MD5      string `json:"md5,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+	Attempts int    `json:"attempts,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+func newManifestEntry(file string, key string, data []byte) ManifestEntry {
+	md5Sum := md5.Sum(data)
+	sha256Sum := sha256.Sum256(data)
+	return ManifestEntry{
+		File:   file,
+		Key:    key,
+		Size:   int64(len(data)),
+		MD5:    hex.EncodeToString(md5Sum[:]),
+		SHA256: hex.EncodeToString(sha256Sum[:]),
+	}
+}
+
+// Orders every list by file, since concurrent uploads finish in any order.
+func (manifest *Manifest) sort() {
+	for _, entries := range [][]ManifestEntry{manifest.Uploaded, manifest.Unchanged, manifest.Failed} {
+		slices.SortFunc(entries, func(a, b ManifestEntry) int { return strings.Compare(a.File, b.File) })
+	}
+}
+
+// Lists the ETag of every object under prefix, except those under any of the excluded prefixes.
+func remoteETags(ctx context.Context, client *s3.Client, bucket string, prefix string, exclude ...string) (map[string]string, error) {
+	etags := map[string]string{}
+	pages := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{Bucket: aws.String(bucket), Prefix: aws.String(prefix)})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+	objects:
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			for _, excluded := range exclude {
+				if excluded != "" && strings.HasPrefix(key, excluded) {
+					continue objects
+				}
+			}
+			etags[key] = strings.Trim(aws.ToString(object.ETag), `"`)
+		}
+	}
+	return etags, nil
+}
+
+// Reports whether the object at entry.Key already holds entry's content. Single-part uploads without KMS encryption
+// have the MD5 of their content as ETag; other ETags don't, so the SHA-256 stored in the object's metadata is compared instead.
+func unchanged(ctx context.Context, client *s3.Client, bucket string, etag string, entry ManifestEntry) (bool, error) {
+	if etag == entry.MD5 {
+		return true, nil
+	}
+	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(entry.Key)})
+	if err != nil {
+		return false, err
+	}
+	return head.Metadata[sha256Metadata] == entry.SHA256, nil
+}
+
+// Deletes keys from the bucket, up to 1000 per request as S3 allows.
+func deleteKeys(ctx context.Context, client *s3.Client, bucket string, keys []string) error {
+	for start := 0; start < len(keys); start += 1000 {
+		var objects []types.ObjectIdentifier
+		for _, key := range keys[start:min(start+1000, len(keys))] {
+			objects = append(objects, types.ObjectIdentifier{Key: aws.String(key)})
+		}
+		output, err := client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return err
+		}
+		// Quiet mode only reports the keys that couldn't be deleted.
+		if len(output.Errors) > 0 {
+			first := output.Errors[0]
+			return fmt.Errorf("deleting %d objects failed, e.g. %s: %s", len(output.Errors), aws.ToString(first.Key), aws.ToString(first.Message))
+		}
+	}
+	return nil
+}
+
+// Writes the manifest of a run to manifests/<run>/manifest.json.
+func putManifest(ctx context.Context, client *s3.Client, manifest Manifest) (string, error) {
+	data, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	key := manifestPrefix + manifest.Run + "/manifest.json"
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(manifest.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	return key, err
+}
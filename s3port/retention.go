@@ -0,0 +1,91 @@
+package s3port
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/retention"
+)
+
+// The snapshots in the bucket, found by reading their section and scrape time back out of settings.KeyTemplate,
+// with archives stored under retention.ArchivePrefix. Manifests and the static API are left alone.
+type RetentionStore struct {
+	settings config.S3
+	layout   keyLayout
+	client   *s3.Client
+}
+
+func NewRetentionStore(ctx context.Context, settings config.S3, sections config.Sections) (*RetentionStore, error) {
+	client, err := newClient(ctx, settings)
+	if err != nil {
+		return nil, err
+	}
+	return &RetentionStore{settings: settings, layout: newKeyLayout(settings, sections), client: client}, nil
+}
+
+func (store *RetentionStore) Name() string {
+	return "s3 " + store.settings.Bucket
+}
+
+func (store *RetentionStore) Snapshots(ctx context.Context) ([]retention.Snapshot, error) {
+	etags, err := remoteETags(ctx, store.client, store.settings.Bucket, "", manifestPrefix, store.settings.Static.Prefix, retention.ArchivePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []retention.Snapshot
+	for key := range etags {
+		if section, scrapedAt, ok := store.layout.parse(key); ok {
+			snapshots = append(snapshots, retention.Snapshot{Name: key, Section: section, ScrapedAt: scrapedAt})
+		}
+	}
+	return snapshots, nil
+}
+
+// Returns the snapshot as scraped, undoing settings.Gzip.
+func (store *RetentionStore) Read(ctx context.Context, key string) ([]byte, error) {
+	object, err := store.client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(store.settings.Bucket), Key: aws.String(key)})
+	var missing *types.NoSuchKey
+	if errors.As(err, &missing) {
+		return nil, fmt.Errorf("%s: %w", key, fs.ErrNotExist)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer object.Body.Close()
+
+	body := io.Reader(object.Body)
+	if aws.ToString(object.ContentEncoding) == "gzip" {
+		reader, err := gzip.NewReader(object.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		body = reader
+	}
+	return io.ReadAll(body)
+}
+
+// Stores an archive, which is compressed already, with the bucket's ACL, storage class and encryption settings.
+func (store *RetentionStore) Write(ctx context.Context, key string, data []byte) error {
+	settings := store.settings
+	settings.Gzip = false
+	settings.ContentType = "application/gzip"
+	sum := sha256.Sum256(data)
+	_, err := store.client.PutObject(ctx, newPutObjectInput(settings, key, data, hex.EncodeToString(sum[:])))
+	return err
+}
+
+func (store *RetentionStore) Delete(ctx context.Context, keys []string) error {
+	return deleteKeys(ctx, store.client, store.settings.Bucket, keys)
+}
@@ -2,107 +2,250 @@ package s3port
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/joho/godotenv"
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/logging"
+	"github.com/mrovengerdev/vlrscrape/metrics"
+	"github.com/mrovengerdev/vlrscrape/retention"
+	"github.com/mrovengerdev/vlrscrape/scrape"
 )
 
 type AWSService struct {
 	S3Client *s3.Client
 }
 
-type fileWalk chan string
+// Syncs every file in the output folder to Amazon S3 and returns what happened to each of them.
+// Files whose content is already in the bucket are skipped, and the rest are uploaded settings.UploadConcurrency at a
+// time, each tried up to settings.UploadAttempts times. With settings.Prune objects whose local file is gone are deleted.
+// A file that keeps failing doesn't stop the others: the returned error joins every failure, and the Manifest, which is
+// also written to the bucket, lists them. With dryRun the bucket isn't changed and the Manifest lists what would be
+// uploaded and pruned.
+// Bucket, region, key layout and credentials come from settings, see newClient. Manifests and the static API published
+// under settings.Static.Prefix are left alone.
+// New files reach S3 through Sink as they are scraped, so this is only needed to copy earlier output into a bucket.
+func Upload(ctx context.Context, logger *slog.Logger, settings config.S3, sections config.Sections, outputDir string, dryRun bool) (Manifest, error) {
+	logger = logger.With("bucket", settings.Bucket, "region", settings.Region)
+	manifest := Manifest{Run: time.Now().UTC().Format(scrape.TimestampLayout), Bucket: settings.Bucket, DryRun: dryRun}
 
-// Traverses files
-func (f fileWalk) Walk(path string, info os.FileInfo, err error) error {
+	// Retrieve path to output file.
+	localPath, err := filepath.Abs(outputDir)
 	if err != nil {
-		return err
+		return manifest, fmt.Errorf("resolving output directory: %w", err)
 	}
-	if !info.IsDir() {
-		f <- path
+	files, err := listFiles(localPath)
+	if err != nil {
+		return manifest, fmt.Errorf("walking output directory: %w", err)
 	}
-	return nil
-}
 
-// Traverses through all files in the output folder through fileWalk and uploads them to Amazon S3.
-// Does so by retrieving credentials, creating a new S3 client, and parsing through the output folder.
-func Upload() {
-	// Retrieve S3 credentials from .env file via godotenv.
-	err := godotenv.Load()
+	client, err := newClient(ctx, settings)
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		return manifest, fmt.Errorf("creating S3 client: %w", err)
+	}
+	syncer, err := newSyncer(ctx, logger, client, settings, "", &manifest, manifestPrefix, settings.Static.Prefix, retention.ArchivePrefix)
+	if err != nil {
+		return manifest, err
 	}
 
-	accessKey := os.Getenv("AWS_VLR_ACCESS_KEY_ID")
-	secretKey := os.Getenv("AWS_VLR_SECRET_KEY")
-	s3Bucket := os.Getenv("AWS_VLR_S3_BUCKET")
-	s3Region := os.Getenv("AWS_VLR_S3_REGION")
-
-	fmt.Println(accessKey, secretKey, s3Bucket, s3Region)
-
-	if accessKey == "" || secretKey == "" {
-		log.Fatal("AWS credentials not found in .env file")
+	layout := newKeyLayout(settings, sections)
+	local := map[string]string{} // Key to the file that claimed it.
+	modTimes := map[string]time.Time{}
+	var jobs []ManifestEntry
+	for _, file := range files {
+		if strings.HasPrefix(file.name, manifestPrefix) {
+			logger.Warn("skipping file under the reserved manifests/ prefix", "file", file.name)
+			manifest.Skipped = append(manifest.Skipped, file.name)
+			continue
+		}
+		if strings.HasPrefix(file.name, retention.ArchivePrefix) {
+			// Retention writes the bucket's archives itself, from the snapshots in the bucket.
+			continue
+		}
+		// Rankings have no timestamp in their name, so their modification time stands in for the scrape time.
+		job := ManifestEntry{File: file.name, Key: layout.key(file.name, file.modTime)}
+		if other, ok := local[job.Key]; ok {
+			syncer.fail(job, fmt.Errorf("key %s is already used by %s, check s3.key_template", job.Key, other))
+			continue
+		}
+		local[job.Key] = file.name
+		modTimes[file.name] = file.modTime
+		jobs = append(jobs, job)
 	}
 
-	// Retrieve path to output file.
-	localPath, err := os.Getwd()
-	if err != nil {
-		log.Fatalf("Error: %v", err)
+	syncer.run(ctx, jobs, func(job ManifestEntry) ([]byte, error) {
+		data, err := os.ReadFile(filepath.Join(localPath, filepath.FromSlash(job.File)))
+		if err != nil {
+			return nil, err
+		}
+		return layout.convert(job.File, modTimes[job.File], data)
+	})
+
+	// Objects without a local file left, e.g. snapshots deleted by hand or by retention, are removed from the bucket.
+	if settings.Prune {
+		syncer.prune(ctx, local)
 	}
-	localPath = filepath.Join(localPath, "output")
 
-	// Creates SDK configuration
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion(s3Region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")))
-	if err != nil {
-		log.Fatalln("error:", err)
+	manifest.sort()
+	var manifestKey string
+	if !dryRun {
+		// Written even when the run was interrupted, so the bucket records how far it got.
+		manifestKey, err = putManifest(context.WithoutCancel(ctx), client, manifest)
+		if err != nil {
+			syncer.errs = append(syncer.errs, fmt.Errorf("writing manifest: %w", err))
+		}
 	}
+	logger.Info("sync complete", "dry_run", dryRun, "uploaded", len(manifest.Uploaded), "unchanged", len(manifest.Unchanged),
+		"skipped", len(manifest.Skipped), "failed", len(manifest.Failed), "pruned", len(manifest.Pruned), "manifest", manifestKey)
+	return manifest, errors.Join(syncer.errs...)
+}
 
-	client := s3.NewFromConfig(cfg)
+type localFile struct {
+	name    string // Slash-separated and relative to the output folder.
+	modTime time.Time
+}
 
-	walker := make(fileWalk)
-	go func() {
-		// Gather the files to upload by walking the path recursively
-		if err := filepath.Walk(localPath, walker.Walk); err != nil {
-			log.Fatalln("Walk failed:", err)
+// Lists the files under dir, leaving out the temporary files of writes still in progress.
+func listFiles(dir string) ([]localFile, error) {
+	var files []localFile
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			return err
 		}
-		close(walker)
-	}()
-
-	// For each file found, walking through output file, upload to Amazon S3
-	uploader := manager.NewUploader(client)
-	for path := range walker {
-		rel, err := filepath.Rel(localPath, path)
+		info, err := entry.Info()
 		if err != nil {
-			log.Fatalln("Unable to get relative path:", path, err)
+			return err
 		}
-		file, err := os.Open(path)
+		rel, err := filepath.Rel(dir, path)
 		if err != nil {
-			log.Println("Failed opening file", path, err)
-			continue
+			return err
 		}
-		defer file.Close()
-
-		// Upload the file to S3 bucket given in .env file.
-		result, err := uploader.Upload(context.TODO(), &s3.PutObjectInput{
-			Bucket: aws.String(s3Bucket),
-			Key:    aws.String(rel), // WIP: Don't need folders in my S3 bucket.
-			Body:   file,
-			ACL:    "public-read",
-		})
-		if err != nil {
-			log.Fatalln("Failed to upload", path, err)
+		files = append(files, localFile{name: filepath.ToSlash(rel), modTime: info.ModTime()})
+		return nil
+	})
+	return files, err
+}
+
+// Creates an S3 client pointed at settings.Endpoint instead of AWS when it's set.
+// Credentials come from the environment variables named by settings.AccessKeyEnv and SecretKeyEnv (e.g. from .env)
+// when both are set, and otherwise from the standard AWS chain: AWS_* variables, settings.Profile or the shared config
+// and credentials files, web identity, and container or instance roles. They are resolved here so missing credentials
+// fail at startup, and never logged.
+func newClient(ctx context.Context, settings config.S3) (*s3.Client, error) {
+	options := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(settings.Region)}
+	if settings.Profile != "" {
+		options = append(options, awsconfig.WithSharedConfigProfile(settings.Profile))
+	}
+
+	accessKey, secretKey := os.Getenv(settings.AccessKeyEnv), os.Getenv(settings.SecretKeyEnv)
+	switch {
+	case accessKey != "" && secretKey != "":
+		logging.Redact(accessKey, secretKey)
+		options = append(options, awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")))
+	case accessKey != "" || secretKey != "":
+		return nil, fmt.Errorf("only one of %s and %s is set", settings.AccessKeyEnv, settings.SecretKeyEnv)
+	}
+
+	// Creates SDK configuration
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("no AWS credentials found: %w", err)
+	}
+	logging.Redact(creds.SecretAccessKey, creds.SessionToken)
+
+	return s3.NewFromConfig(cfg, func(options *s3.Options) {
+		if settings.Endpoint != "" {
+			options.BaseEndpoint = aws.String(settings.Endpoint)
 		}
+		options.UsePathStyle = settings.PathStyle
+	}), nil
+}
+
+// Writes output files straight to an S3 bucket as they are scraped, keyed by settings.KeyTemplate and converted to
+// settings.Format.
+type Sink struct {
+	settings config.S3
+	layout   keyLayout
+	client   *s3.Client
+
+	mu   sync.Mutex
+	keys map[string]string // The file each key was written from in the current run.
+}
 
-		log.Printf("Uploaded the file: %s to S3 location: %s\n", path, result.Location)
+func NewSink(ctx context.Context, settings config.S3, sections config.Sections) (*Sink, error) {
+	client, err := newClient(ctx, settings)
+	if err != nil {
+		return nil, err
 	}
+	return &Sink{settings: settings, layout: newKeyLayout(settings, sections), client: client, keys: map[string]string{}}, nil
+}
+
+// Forgets the keys written so far, so the next run may write them again.
+func (sink *Sink) BeginRun() {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.keys = map[string]string{}
+}
+
+// Claims key for name in the current run, failing when another file of the run was already written under it.
+func (sink *Sink) claim(key, name string) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if other, ok := sink.keys[key]; ok && other != name {
+		return fmt.Errorf("key %s is already used by %s in this run, check s3.key_template", key, other)
+	}
+	sink.keys[key] = name
+	return nil
+}
+
+func (sink *Sink) Name() string {
+	return "s3 " + sink.settings.Bucket
+}
+
+func (sink *Sink) Write(ctx context.Context, name string, data []byte) error {
+	now := time.Now()
+	key := sink.layout.key(name, now)
+	if err := sink.claim(key, name); err != nil {
+		return err
+	}
+	settings := objectSettings(sink.settings, key)
+	data, err := sink.layout.convert(name, now, data)
+	if err != nil {
+		return err
+	}
+	body, err := encodeBody(settings, data)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	sum := sha256.Sum256(body)
+	_, err = sink.client.PutObject(ctx, newPutObjectInput(settings, key, body, hex.EncodeToString(sum[:])))
+	metrics.UploadDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.UploadFailures.Inc()
+		return err
+	}
+	metrics.UploadBytes.Add(float64(len(body)))
+	return nil
+}
+
+func (sink *Sink) Close() error {
+	return nil
 }
@@ -0,0 +1,117 @@
+package s3port
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/logging"
+)
+
+func TestSinkRefusesDuplicateKeys(t *testing.T) {
+	fake := newFakeS3()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	settings := fakeS3Settings(t, server.URL)
+	// Validation rejects this template with rankings enabled, since every region maps to the same key.
+	settings.KeyTemplate = "{section}/{YYYY-MM-DD}.json"
+	ctx := context.Background()
+	sink, err := NewSink(ctx, settings, config.Default().Sections)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Write(ctx, "ranking/outputEuropeRankings.json", []byte(`[]`)); err != nil {
+		t.Fatal(err)
+	}
+	// Rewriting the same file in a run replaces it, e.g. when a scraper retries.
+	if err := sink.Write(ctx, "ranking/outputEuropeRankings.json", []byte(`[]`)); err != nil {
+		t.Errorf("rewriting a file: %v", err)
+	}
+	err = sink.Write(ctx, "ranking/outputNorth-AmericaRankings.json", []byte(`[{"rank": 1}]`))
+	if err == nil || !strings.Contains(err.Error(), "already used by ranking/outputEuropeRankings.json") {
+		t.Errorf("writing another file to the same key returned %v", err)
+	}
+	if keys := fake.keys(); len(keys) != 1 || string(fake.objects[keys[0]].body) != `[]` {
+		t.Errorf("bucket holds %v, want only Europe's rankings", keys)
+	}
+
+	sink.BeginRun()
+	if err := sink.Write(ctx, "ranking/outputNorth-AmericaRankings.json", []byte(`[{"rank": 1}]`)); err != nil {
+		t.Errorf("writing the key in the next run: %v", err)
+	}
+}
+
+func TestNewClientCredentials(t *testing.T) {
+	// Only what each case sets is visible to the SDK: no shared files, instance roles or AWS_* variables of the host.
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_DEFAULT_PROFILE",
+		"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN", "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_CONTAINER_CREDENTIALS_FULL_URI"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	dir := t.TempDir()
+	credentialsFile := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(credentialsFile, []byte("[scraper]\naws_access_key_id = PROFILEKEYID\naws_secret_access_key = profile-secret-value\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		profile string
+		keyID   string
+		secret  string
+		err     string // Part of the expected error, empty when a client should be created.
+	}{
+		{name: "keys from .env override the chain", env: map[string]string{
+			"TEST_S3_ACCESS_KEY": "ENVKEYID", "TEST_S3_SECRET_KEY": "env-secret-value", "AWS_ACCESS_KEY_ID": "CHAINKEYID", "AWS_SECRET_ACCESS_KEY": "chain-secret-value",
+		}, keyID: "ENVKEYID", secret: "env-secret-value"},
+		{name: "only one .env key", env: map[string]string{"TEST_S3_ACCESS_KEY": "ENVKEYID"}, err: "only one of TEST_S3_ACCESS_KEY and TEST_S3_SECRET_KEY"},
+		{name: "AWS variables", env: map[string]string{"AWS_ACCESS_KEY_ID": "CHAINKEYID", "AWS_SECRET_ACCESS_KEY": "chain-secret-value"}, keyID: "CHAINKEYID", secret: "chain-secret-value"},
+		{name: "shared credentials profile", profile: "scraper", keyID: "PROFILEKEYID", secret: "profile-secret-value"},
+		{name: "no credentials", err: "no AWS credentials found"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+			settings := config.Default().S3
+			settings.Region = "us-east-1"
+			settings.Profile = test.profile
+			settings.AccessKeyEnv = "TEST_S3_ACCESS_KEY"
+			settings.SecretKeyEnv = "TEST_S3_SECRET_KEY"
+
+			client, err := newClient(context.Background(), settings)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("newClient returned %v, want an error containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			creds, err := client.Options().Credentials.Retrieve(context.Background())
+			if err != nil || creds.AccessKeyID != test.keyID || creds.SecretAccessKey != test.secret {
+				t.Errorf("credentials = %s, %v, want key %s", creds.AccessKeyID, err, test.keyID)
+			}
+
+			// Whichever source they came from, the secrets are redacted from the logs.
+			var buffer bytes.Buffer
+			logging.New(config.Default().Log, &buffer).Info("signing", "detail", "secret "+test.secret)
+			if strings.Contains(buffer.String(), test.secret) {
+				t.Errorf("the secret key was logged: %s", buffer.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package s3port
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/scrape"
+)
+
+// Publishes a static API tree, as built by restAPI.StaticTree, under settings.Static.Prefix so a CDN can serve it.
+// Only changed documents are uploaded, and objects under the prefix that are no longer in the tree are deleted, so the
+// prefix must be reserved for the static API. Failures are reported like Upload's, and with dryRun nothing is changed.
+func Publish(ctx context.Context, logger *slog.Logger, settings config.S3, tree map[string][]byte, dryRun bool) (Manifest, error) {
+	prefix := settings.Static.Prefix
+	logger = logger.With("bucket", settings.Bucket, "prefix", prefix)
+	manifest := Manifest{Run: time.Now().UTC().Format(scrape.TimestampLayout), Bucket: settings.Bucket, DryRun: dryRun}
+
+	client, err := newClient(ctx, settings)
+	if err != nil {
+		return manifest, fmt.Errorf("creating S3 client: %w", err)
+	}
+	syncer, err := newSyncer(ctx, logger, client, settings, prefix, &manifest)
+	if err != nil {
+		return manifest, err
+	}
+	syncer.cacheControl = settings.Static.CacheControl
+
+	read := func(job ManifestEntry) ([]byte, error) { return tree[job.File], nil }
+	keep := map[string]string{}
+	var jobs []ManifestEntry
+	for file := range tree {
+		keep[prefix+file] = file
+		if file != "index.json" {
+			jobs = append(jobs, ManifestEntry{File: file, Key: prefix + file})
+		}
+	}
+	syncer.run(ctx, jobs, read)
+	// index.json goes last so it never links to documents that aren't there yet.
+	if _, ok := tree["index.json"]; ok {
+		syncer.run(ctx, []ManifestEntry{{File: "index.json", Key: prefix + "index.json"}}, read)
+	}
+
+	// Documents of entities that have left the output, e.g. teams that dropped out of the rankings, are removed.
+	syncer.prune(ctx, keep)
+
+	manifest.sort()
+	logger.Info("static API published", "dry_run", dryRun, "uploaded", len(manifest.Uploaded), "unchanged", len(manifest.Unchanged),
+		"failed", len(manifest.Failed), "pruned", len(manifest.Pruned))
+	return manifest, errors.Join(syncer.errs...)
+}
@@ -0,0 +1,47 @@
+package s3port
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestPublish(t *testing.T) {
+	fake := newFakeS3()
+	fake.put("api/teams/fnatic.json", `{"name": "FNATIC"}`, "", sha256Hex(`{"name": "FNATIC"}`))
+	fake.put("api/teams/retired.json", `{"name": "Retired"}`, "", "")
+	fake.put("outputThreads_2024-11-06_15-04-05.json", `[]`, "", "")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	tree := map[string][]byte{
+		"index.json":           []byte(`{}`),
+		"latest/threads.json":  []byte(`[]`),
+		"teams/fnatic.json":    []byte(`{"name": "FNATIC"}`),
+		"teams/sentinels.json": []byte(`{"name": "Sentinels"}`),
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	manifest, err := Publish(context.Background(), logger, fakeS3Settings(t, server.URL), tree, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// index.json only links to documents once they are in the bucket.
+	if len(fake.stored) == 0 || fake.stored[len(fake.stored)-1] != "api/index.json" {
+		t.Errorf("stored %v, want api/index.json last", fake.stored)
+	}
+	if fake.puts["api/teams/fnatic.json"] != 0 || len(manifest.Unchanged) != 1 {
+		t.Errorf("unchanged document was uploaded again, manifest lists %d unchanged", len(manifest.Unchanged))
+	}
+	// Documents no longer in the tree are pruned, and nothing outside the prefix is touched.
+	want := []string{"api/index.json", "api/latest/threads.json", "api/teams/fnatic.json", "api/teams/sentinels.json", "outputThreads_2024-11-06_15-04-05.json"}
+	if keys := fake.keys(); !slices.Equal(keys, want) {
+		t.Errorf("bucket holds %v, want %v", keys, want)
+	}
+	if !slices.Equal(manifest.Pruned, []string{"api/teams/retired.json"}) {
+		t.Errorf("pruned %v, want api/teams/retired.json", manifest.Pruned)
+	}
+}
@@ -0,0 +1,172 @@
+package s3port
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/mrovengerdev/vlrscrape/config"
+	"github.com/mrovengerdev/vlrscrape/logging"
+	"github.com/mrovengerdev/vlrscrape/metrics"
+)
+
+// Uploads a set of objects to one part of a bucket, skipping those whose content is already there, and records what
+// happened to each of them in a Manifest. Shared by the workers of one Upload or Publish.
+type syncer struct {
+	settings     config.S3
+	client       *s3.Client
+	uploader     *manager.Uploader
+	etags        map[string]string // Key to ETag of the objects already in the synced part of the bucket.
+	cacheControl string
+	dryRun       bool
+	logger       *slog.Logger
+
+	mu       sync.Mutex
+	manifest *Manifest
+	errs     []error
+}
+
+// Lists the objects under prefix, except those under any of the excluded prefixes, which the sync never touches.
+func newSyncer(ctx context.Context, logger *slog.Logger, client *s3.Client, settings config.S3, prefix string, manifest *Manifest, exclude ...string) (*syncer, error) {
+	etags, err := remoteETags(ctx, client, settings.Bucket, prefix, exclude...)
+	if err != nil {
+		return nil, fmt.Errorf("listing bucket: %w", err)
+	}
+	return &syncer{
+		settings: settings,
+		client:   client,
+		uploader: manager.NewUploader(client),
+		etags:    etags,
+		dryRun:   manifest.DryRun,
+		logger:   logger,
+		manifest: manifest,
+	}, nil
+}
+
+// Records an object that couldn't be uploaded.
+func (syncer *syncer) fail(entry ManifestEntry, err error) {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+	metrics.UploadFailures.Inc()
+	entry.Error = err.Error()
+	syncer.manifest.Failed = append(syncer.manifest.Failed, entry)
+	syncer.errs = append(syncer.errs, fmt.Errorf("%s: %w", entry.File, err))
+	syncer.logger.Error("upload failed", "file", entry.File, "key", entry.Key, logging.Err(err))
+}
+
+// Syncs every job, settings.UploadConcurrency at a time, reading the content of each through read.
+func (syncer *syncer) run(ctx context.Context, jobs []ManifestEntry, read func(ManifestEntry) ([]byte, error)) {
+	// Workers take jobs off queue until it's closed.
+	queue := make(chan ManifestEntry)
+	var workers sync.WaitGroup
+	for range syncer.settings.UploadConcurrency {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range queue {
+				data, err := read(job)
+				if err != nil {
+					syncer.fail(job, err)
+					continue
+				}
+				entry, uploaded, err := syncer.put(ctx, job, data)
+				if err != nil {
+					syncer.fail(entry, err)
+					continue
+				}
+				syncer.mu.Lock()
+				if uploaded {
+					syncer.manifest.Uploaded = append(syncer.manifest.Uploaded, entry)
+				} else {
+					syncer.manifest.Unchanged = append(syncer.manifest.Unchanged, entry)
+				}
+				syncer.mu.Unlock()
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	workers.Wait()
+}
+
+// Uploads data under job.Key unless the bucket already holds it, retrying with backoff.
+// Reports whether it was uploaded, or in a dry run whether it would be.
+func (syncer *syncer) put(ctx context.Context, job ManifestEntry, data []byte) (ManifestEntry, bool, error) {
+	settings := objectSettings(syncer.settings, job.Key)
+	data, err := encodeBody(settings, data)
+	if err != nil {
+		return job, false, err
+	}
+	entry := newManifestEntry(job.File, job.Key, data)
+
+	if etag, ok := syncer.etags[entry.Key]; ok {
+		same, err := unchanged(ctx, syncer.client, syncer.settings.Bucket, etag, entry)
+		if err != nil {
+			syncer.logger.Warn("comparing with the bucket failed, uploading again", "key", entry.Key, logging.Err(err))
+		}
+		if same {
+			syncer.logger.Debug("skipping unchanged file", "file", entry.File, "key", entry.Key)
+			return entry, false, nil
+		}
+	}
+	if syncer.dryRun {
+		syncer.logger.Info("would upload", "file", entry.File, "key", entry.Key, "size", entry.Size)
+		return entry, true, nil
+	}
+
+	for entry.Attempts = 1; ; entry.Attempts++ {
+		// Built for every attempt, since a failed one may have consumed the body.
+		input := newPutObjectInput(settings, entry.Key, data, entry.SHA256)
+		if syncer.cacheControl != "" {
+			input.CacheControl = aws.String(syncer.cacheControl)
+		}
+		start := time.Now()
+		result, err := syncer.uploader.Upload(ctx, input)
+		metrics.UploadDuration.Observe(time.Since(start).Seconds())
+		if err == nil {
+			metrics.UploadBytes.Add(float64(entry.Size))
+			syncer.logger.Info("uploaded file", "file", entry.File, "key", entry.Key, "location", result.Location)
+			return entry, true, nil
+		}
+		if entry.Attempts >= syncer.settings.UploadAttempts {
+			return entry, false, err
+		}
+
+		backoff := time.Duration(1<<(entry.Attempts-1)) * time.Second
+		syncer.logger.Warn("upload failed, retrying", "key", entry.Key, "attempt", entry.Attempts, "backoff", backoff, logging.Err(err))
+		select {
+		case <-ctx.Done():
+			return entry, false, ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// Deletes the synced objects whose key isn't in keep, or in a dry run only lists them.
+func (syncer *syncer) prune(ctx context.Context, keep map[string]string) {
+	for key := range syncer.etags {
+		if _, ok := keep[key]; !ok {
+			syncer.manifest.Pruned = append(syncer.manifest.Pruned, key)
+		}
+	}
+	sort.Strings(syncer.manifest.Pruned)
+	if syncer.dryRun {
+		for _, key := range syncer.manifest.Pruned {
+			syncer.logger.Info("would prune", "key", key)
+		}
+		return
+	}
+	if err := deleteKeys(ctx, syncer.client, syncer.settings.Bucket, syncer.manifest.Pruned); err != nil {
+		syncer.errs = append(syncer.errs, fmt.Errorf("pruning bucket: %w", err))
+		syncer.manifest.Pruned = nil
+	}
+}
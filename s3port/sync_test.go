@@ -0,0 +1,295 @@
+package s3port
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mrovengerdev/vlrscrape/config"
+)
+
+type fakeObject struct {
+	body     []byte
+	etag     string
+	metadata map[string]string
+}
+
+// Just enough of the S3 API for Upload, served path-style: ListObjectsV2, HeadObject, PutObject and DeleteObjects.
+// PutObject fails with AccessDenied, which the SDK doesn't retry, while failures[key] is above zero.
+type fakeS3 struct {
+	mu       sync.Mutex
+	objects  map[string]fakeObject
+	failures map[string]int
+	puts     map[string]int
+	stored   []string // Keys in the order they were stored.
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: map[string]fakeObject{}, failures: map[string]int{}, puts: map[string]int{}}
+}
+
+func (fake *fakeS3) put(key string, body string, etag string, sha string) {
+	if etag == "" {
+		sum := md5.Sum([]byte(body))
+		etag = hex.EncodeToString(sum[:])
+	}
+	fake.objects[key] = fakeObject{body: []byte(body), etag: etag, metadata: map[string]string{sha256Metadata: sha}}
+}
+
+func (fake *fakeS3) keys() []string {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	var keys []string
+	for key := range fake.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (fake *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	_, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		type content struct {
+			Key  string
+			ETag string
+			Size int
+		}
+		result := struct {
+			XMLName     xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+			IsTruncated bool
+			KeyCount    int
+			Contents    []content
+		}{}
+		for name, object := range fake.objects {
+			if strings.HasPrefix(name, r.URL.Query().Get("prefix")) {
+				result.Contents = append(result.Contents, content{Key: name, ETag: `"` + object.etag + `"`, Size: len(object.body)})
+			}
+		}
+		result.KeyCount = len(result.Contents)
+		xml.NewEncoder(w).Encode(result)
+
+	case r.Method == http.MethodHead:
+		object, ok := fake.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"`+object.etag+`"`)
+		for name, value := range object.metadata {
+			w.Header().Set("X-Amz-Meta-"+name, value)
+		}
+
+	case r.Method == http.MethodPut:
+		fake.puts[key]++
+		if fake.failures[key] > 0 {
+			fake.failures[key]--
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>denied</Message></Error>`)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		sum := md5.Sum(body)
+		etag := hex.EncodeToString(sum[:])
+		fake.objects[key] = fakeObject{body: body, etag: etag, metadata: map[string]string{sha256Metadata: r.Header.Get("X-Amz-Meta-" + sha256Metadata)}}
+		fake.stored = append(fake.stored, key)
+		w.Header().Set("ETag", `"`+etag+`"`)
+
+	case r.Method == http.MethodPost && r.URL.Query().Has("delete"):
+		var request struct {
+			Objects []struct{ Key string } `xml:"Object"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, object := range request.Objects {
+			delete(fake.objects, object.Key)
+		}
+		fmt.Fprint(w, `<DeleteResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></DeleteResult>`)
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// Returns settings for the fake S3 served at url, with test credentials set in the environment.
+func fakeS3Settings(t *testing.T, url string) config.S3 {
+	t.Setenv("TEST_S3_ACCESS_KEY", "access")
+	t.Setenv("TEST_S3_SECRET_KEY", "secret")
+	settings := config.Default().S3
+	settings.Bucket = "bucket"
+	settings.Region = "us-east-1"
+	settings.Endpoint = url
+	settings.PathStyle = true
+	settings.AccessKeyEnv = "TEST_S3_ACCESS_KEY"
+	settings.SecretKeyEnv = "TEST_S3_SECRET_KEY"
+	return settings
+}
+
+func sha256Hex(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUploadSync(t *testing.T) {
+	local := map[string]string{
+		"outputThreads_2024-11-06_15-04-05.json": `[{"id": 1}]`, // Same MD5 ETag in the bucket.
+		"outputThreads_2024-11-06_16-04-05.json": `[{"id": 2}]`, // Multipart ETag in the bucket, same SHA-256.
+		"outputMatches_2024-11-06_15-04-05.json": `[{"id": 3}]`, // Changed since the bucket's copy.
+		"ranking/outputEuropeRankings.json":      `[]`,          // Not in the bucket.
+	}
+	// The bucket before each run. manifests/, api/ and archive/ are never synced.
+	seed := func(fake *fakeS3) {
+		fake.put("outputThreads_2024-11-06_15-04-05.json", `[{"id": 1}]`, "", "")
+		fake.put("outputThreads_2024-11-06_16-04-05.json", `[{"id": 2}]`, "0123456789abcdef-2", sha256Hex(`[{"id": 2}]`))
+		fake.put("outputMatches_2024-11-06_15-04-05.json", `[{"id": 0}]`, "", "")
+		fake.put("outputThreads_2024-11-01_15-04-05.json", `[]`, "", "")
+		fake.put("manifests/2024-11-01_00-00-00/manifest.json", `{}`, "", "")
+		fake.put("api/index.json", `{}`, "", "")
+		fake.put("archive/threads/2024-10-01.ndjson.gz", ``, "", "")
+	}
+
+	tests := []struct {
+		name      string
+		prune     bool
+		dryRun    bool
+		failures  map[string]int
+		uploaded  []string
+		unchanged []string
+		failed    []string
+		pruned    []string
+		attempts  map[string]int // Attempts recorded in the manifest, by file.
+		remaining []string       // Synced keys left in the bucket afterwards, leaving out manifests/.
+	}{
+		{
+			name:      "skips unchanged",
+			uploaded:  []string{"outputMatches_2024-11-06_15-04-05.json", "ranking/outputEuropeRankings.json"},
+			unchanged: []string{"outputThreads_2024-11-06_15-04-05.json", "outputThreads_2024-11-06_16-04-05.json"},
+			remaining: []string{"api/index.json", "archive/threads/2024-10-01.ndjson.gz", "outputMatches_2024-11-06_15-04-05.json", "outputThreads_2024-11-01_15-04-05.json", "outputThreads_2024-11-06_15-04-05.json", "outputThreads_2024-11-06_16-04-05.json", "ranking/outputEuropeRankings.json"},
+		},
+		{
+			name:      "prunes",
+			prune:     true,
+			uploaded:  []string{"outputMatches_2024-11-06_15-04-05.json", "ranking/outputEuropeRankings.json"},
+			unchanged: []string{"outputThreads_2024-11-06_15-04-05.json", "outputThreads_2024-11-06_16-04-05.json"},
+			pruned:    []string{"outputThreads_2024-11-01_15-04-05.json"},
+			remaining: []string{"api/index.json", "archive/threads/2024-10-01.ndjson.gz", "outputMatches_2024-11-06_15-04-05.json", "outputThreads_2024-11-06_15-04-05.json", "outputThreads_2024-11-06_16-04-05.json", "ranking/outputEuropeRankings.json"},
+		},
+		{
+			name:      "retries",
+			failures:  map[string]int{"outputMatches_2024-11-06_15-04-05.json": 1, "ranking/outputEuropeRankings.json": 5},
+			uploaded:  []string{"outputMatches_2024-11-06_15-04-05.json"},
+			unchanged: []string{"outputThreads_2024-11-06_15-04-05.json", "outputThreads_2024-11-06_16-04-05.json"},
+			failed:    []string{"ranking/outputEuropeRankings.json"},
+			attempts:  map[string]int{"outputMatches_2024-11-06_15-04-05.json": 2, "ranking/outputEuropeRankings.json": 2},
+			remaining: []string{"api/index.json", "archive/threads/2024-10-01.ndjson.gz", "outputMatches_2024-11-06_15-04-05.json", "outputThreads_2024-11-01_15-04-05.json", "outputThreads_2024-11-06_15-04-05.json", "outputThreads_2024-11-06_16-04-05.json"},
+		},
+		{
+			name:      "dry run",
+			prune:     true,
+			dryRun:    true,
+			uploaded:  []string{"outputMatches_2024-11-06_15-04-05.json", "ranking/outputEuropeRankings.json"},
+			unchanged: []string{"outputThreads_2024-11-06_15-04-05.json", "outputThreads_2024-11-06_16-04-05.json"},
+			pruned:    []string{"outputThreads_2024-11-01_15-04-05.json"},
+			remaining: []string{"api/index.json", "archive/threads/2024-10-01.ndjson.gz", "outputMatches_2024-11-06_15-04-05.json", "outputThreads_2024-11-01_15-04-05.json", "outputThreads_2024-11-06_15-04-05.json", "outputThreads_2024-11-06_16-04-05.json"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			for name, body := range local {
+				path := filepath.Join(outputDir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			fake := newFakeS3()
+			seed(fake)
+			for key, count := range test.failures {
+				fake.failures[key] = count
+			}
+			server := httptest.NewServer(fake)
+			defer server.Close()
+
+			settings := fakeS3Settings(t, server.URL)
+			settings.UploadAttempts = 2
+			settings.Prune = test.prune
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			manifest, err := Upload(context.Background(), logger, settings, config.Default().Sections, outputDir, test.dryRun)
+			if (err != nil) != (len(test.failed) > 0) {
+				t.Fatalf("Upload returned %v, want failures for %v", err, test.failed)
+			}
+
+			files := func(entries []ManifestEntry) []string {
+				var names []string
+				for _, entry := range entries {
+					names = append(names, entry.File)
+				}
+				return names
+			}
+			for _, list := range []struct {
+				name string
+				got  []string
+				want []string
+			}{
+				{"uploaded", files(manifest.Uploaded), test.uploaded},
+				{"unchanged", files(manifest.Unchanged), test.unchanged},
+				{"failed", files(manifest.Failed), test.failed},
+				{"pruned", manifest.Pruned, test.pruned},
+			} {
+				if !slices.Equal(list.got, list.want) {
+					t.Errorf("%s = %v, want %v", list.name, list.got, list.want)
+				}
+			}
+			for _, entry := range append(manifest.Uploaded, manifest.Failed...) {
+				if want, ok := test.attempts[entry.File]; ok && entry.Attempts != want {
+					t.Errorf("%s took %d attempts, want %d", entry.File, entry.Attempts, want)
+				}
+			}
+
+			var remaining []string
+			for _, key := range fake.keys() {
+				if !strings.HasPrefix(key, manifestPrefix) {
+					remaining = append(remaining, key)
+				}
+			}
+			if !slices.Equal(remaining, test.remaining) {
+				t.Errorf("bucket holds %v, want %v", remaining, test.remaining)
+			}
+
+			// A manifest is written for every run but a dry one.
+			manifests := 0
+			for _, key := range fake.keys() {
+				if strings.HasPrefix(key, manifestPrefix) {
+					manifests++
+				}
+			}
+			if want := map[bool]int{true: 1, false: 2}[test.dryRun]; manifests != want {
+				t.Errorf("bucket holds %d manifests, want %d", manifests, want)
+			}
+		})
+	}
+}
@@ -1,75 +1,127 @@
 package scrape
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
+	"path"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/mrovengerdev/vlrscrape/logging"
+	"github.com/mrovengerdev/vlrscrape/metrics"
+	"github.com/mrovengerdev/vlrscrape/model"
 	"github.com/mrovengerdev/vlrscrape/paginator"
 	"github.com/mrovengerdev/vlrscrape/scrapetools"
+	"github.com/mrovengerdev/vlrscrape/sink"
 )
 
-// Struct Catalog
-type Thread struct {
-	ID               int    `json:"id"`
-	Title            string `json:"title"`
-	ThreadURL        string `json:"thread_url"`
-	FragCount        int    `json:"frag_count"`
-	DatePublished    string `json:"date_published"`
-	DatePublishedAgo string `json:"date_published_ago"`
-	CommentCount     int    `json:"comment_count"`
-}
+// The scraped types live in model so that API clients can share them without depending on the scraper.
+type (
+	Thread  = model.Thread
+	Match   = model.Match
+	Ranking = model.Ranking
+)
 
-type Match struct {
-	ID             int    `json:"id"`
-	MatchURL       string `json:"match_url"`
-	Tournament     string `json:"tournament"`
-	Team1          string `json:"team1"`
-	Team2          string `json:"team2"`
-	Date           string `json:"date"`
-	MatchTime      string `json:"match_time"`
-	TimeUntilMatch string `json:"time_until_match"` // Time until match
-}
+var base_url = "https://www.vlr.gg"
+
+// Layout of the timestamp PageParser appends to each output file name.
+const TimestampLayout = "2006-01-02_15-04-05"
 
-type Ranking struct {
-	Rank     int    `json:"rank"`
-	Region   string `json:"region"`
-	TeamName string `json:"team_name"`
-	ELO      int    `json:"elo"`
-	TeamURL  string `json:"team_url"`
+// Overrides the site root that scraped URLs are built from.
+func SetBaseURL(url string) {
+	base_url = url
 }
 
-const base_url = "https://www.vlr.gg"
+// Time zone thread dates are read in, see SetSourceLocation.
+var sourceLocation = time.UTC
+
+// Sets the time zone vlr.gg writes thread dates in, since they carry no offset.
+func SetSourceLocation(location *time.Location) {
+	sourceLocation = location
+}
 
 // Makes connection to scraping destination and returns document for parsing.
-func ScrapePrep(url string) *goquery.Document {
-	response, err := http.Get(url)
+// logger is expected to carry the URL being fetched, so lines about the page can be traced back to it.
+func ScrapePrep(logger *slog.Logger, url string) (*goquery.Document, error) {
+	response, err := get(sectionOf(url), url)
 	if err != nil {
-		log.Fatalf("Error: %v", err)
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
 	}
 	defer response.Body.Close()
 
-	if response.StatusCode == 200 {
-		fmt.Printf("Success: %d at %s \n", response.StatusCode, url)
+	if response.StatusCode == http.StatusOK {
+		logger.Debug("fetched page", "status", response.StatusCode)
 	} else {
-		fmt.Println("Error: ", err)
+		logger.Warn("unexpected status, parsing anyway", "status", response.StatusCode)
 	}
 
 	doc, err := goquery.NewDocumentFromReader(response.Body)
 	if err != nil {
-		log.Fatalf("Error: %v", err)
+		return nil, fmt.Errorf("reading %s: %w", url, err)
+	}
+	return doc, nil
+}
+
+// Fetches a page for parsing like ScrapePrep, but quietly and failing on any status other than 200, for polling in the background.
+func fetchDocument(section string, url string) (*goquery.Document, error) {
+	response, err := get(section, url)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d at %s", response.StatusCode, url)
+	}
+
+	return goquery.NewDocumentFromReader(response.Body)
+}
+
+// Bounds every request to vlr.gg, including reading the body, so a stalled connection can't hang a scrape.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Sends a GET request to url, recording its status and latency under section.
+func get(section string, url string) (*http.Response, error) {
+	start := time.Now()
+	response, err := httpClient.Get(url)
+	metrics.ObserveSince(metrics.FetchDuration, start, section)
+	if err != nil {
+		metrics.FetchesTotal.WithLabelValues(section, "error").Inc()
+		return nil, err
+	}
+	metrics.FetchesTotal.WithLabelValues(section, strconv.Itoa(response.StatusCode)).Inc()
+	return response, nil
+}
+
+// Returns the section a URL under base_url belongs to for metrics: threads, matches or rankings,
+// and "match" for individual match pages.
+func sectionOf(url string) string {
+	rest := strings.TrimPrefix(strings.TrimPrefix(url, base_url), "/")
+	section, _, _ := strings.Cut(rest, "/")
+	section, _, _ = strings.Cut(section, "?")
+	switch section {
+	case "threads", "matches", "rankings":
+		return section
+	default:
+		return "match"
 	}
-	return doc
+}
+
+// Counts an item that couldn't be parsed and logs why, so the scrape can carry on without it.
+func skipItem(logger *slog.Logger, section string, err error) {
+	metrics.ParseErrors.WithLabelValues(section).Inc()
+	logger.Warn("skipping item that couldn't be parsed", logging.Err(err))
 }
 
 // Scrape threads from vlr.gg/threads. Returns JSON data as []byte.
-func threadScrape(currentPage int, doc *goquery.Document) []byte {
+func threadScrape(logger *slog.Logger, currentPage int, doc *goquery.Document) ([]byte, error) {
 
 	var threads []Thread
 
@@ -77,80 +129,119 @@ func threadScrape(currentPage int, doc *goquery.Document) []byte {
 	// Retrieves the first 3 threads only for the first page, since they repeat on every page.
 	doc.Find("div.thread.wf-module-item.mod-color.mod-left.mod-bg-after-.unread").Each(func(index int, item *goquery.Selection) { // Two wf-cards so double for-loop required
 		// Ignore first 3 posts since they are always the same
-		if currentPage == 1 {
-			// Upvote count processing (string to int)
-			tempFragCount, err := strconv.Atoi(strings.TrimSpace(item.Find("span.frag-count").Text()))
-			if err != nil {
-				log.Fatalf("Error: %v", err)
-			}
-			tempID, err := strconv.Atoi(item.Find("div.block.frag.frag-container.noselect.neutral").AttrOr("data-thread-id", ""))
-			if err != nil {
-				log.Fatalf("Error: %v", err)
-			}
+		if currentPage != 1 && index <= 2 {
+			return
+		}
 
-			// Comment count processing (string to int)
-			tempCommentCount := strings.TrimSpace(item.Find("span.post-count").Text())
-			tempCommentCount = strings.ReplaceAll(tempCommentCount, "\t\t\t\t\t\t\t\t\t\t\t\t\t", " ")
-			commentNum, err := strconv.Atoi(strings.Split(tempCommentCount, " ")[0])
-			if err != nil {
-				log.Fatalf("Error: %v", err)
-			}
+		thread, err := parseThreadItem(item, time.Now())
+		if err != nil {
+			skipItem(logger, "threads", err)
+			return
+		}
 
-			thread := Thread{
-				ID:               tempID,
-				Title:            strings.TrimSpace(item.Find(".thread-item-header-title").Text()),
-				ThreadURL:        base_url + item.Find(".thread-item-header-title").AttrOr("href", ""),
-				FragCount:        tempFragCount,
-				DatePublished:    strings.TrimSpace(item.Find("span.date-full.hide").Text()),
-				DatePublishedAgo: strings.TrimSpace(item.Find("span.js-date-toggle.date-eta").Text()),
-				CommentCount:     commentNum,
-			}
+		threads = append(threads, thread)
+		metrics.ItemsParsed.WithLabelValues("threads").Inc()
+	})
 
-			threads = append(threads, thread)
-		} else {
-			if index > 2 {
-				// Upvote count processing (string to int)
-				tempFragCount, err := strconv.Atoi(strings.TrimSpace(item.Find("span.frag-count").Text()))
-				if err != nil {
-					log.Fatalf("Error: %v", err)
-				}
-				tempID, err := strconv.Atoi(item.Find("div.block.frag.frag-container.noselect.neutral").AttrOr("data-thread-id", ""))
-				if err != nil {
-					log.Fatalf("Error: %v", err)
-				}
+	// Converts data format to JSON
+	jsonData, err := json.MarshalIndent(threads, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding threads: %w", err)
+	}
 
-				// Comment count processing (string to int)
-				tempCommentCount := strings.TrimSpace(item.Find("span.post-count").Text())
-				tempCommentCount = strings.ReplaceAll(tempCommentCount, "\t\t\t\t\t\t\t\t\t\t\t\t\t", " ")
-				commentNum, err := strconv.Atoi(strings.Split(tempCommentCount, " ")[0])
-				if err != nil {
-					log.Fatalf("Error: %v", err)
-				}
+	logger.Info("thread page scraped", "items", len(threads))
 
-				thread := Thread{
-					ID:               tempID,
-					Title:            strings.TrimSpace(item.Find(".thread-item-header-title").Text()),
-					ThreadURL:        base_url + item.Find(".thread-item-header-title").AttrOr("href", ""),
-					FragCount:        tempFragCount,
-					DatePublished:    strings.TrimSpace(item.Find("span.date-full.hide").Text()),
-					DatePublishedAgo: strings.TrimSpace(item.Find("span.js-date-toggle.date-eta").Text()),
-					CommentCount:     commentNum,
-				}
+	return jsonData, nil
+}
 
-				threads = append(threads, thread)
-			}
-		}
-	})
+// Parses a single thread from the thread list, scraped at now.
+func parseThreadItem(item *goquery.Selection, now time.Time) (Thread, error) {
+	// Upvote count processing (string to int)
+	tempFragCount, err := strconv.Atoi(strings.TrimSpace(item.Find("span.frag-count").Text()))
+	if err != nil {
+		return Thread{}, fmt.Errorf("unexpected frag count: %w", err)
+	}
+	tempID, err := strconv.Atoi(item.Find("div.block.frag.frag-container.noselect.neutral").AttrOr("data-thread-id", ""))
+	if err != nil {
+		return Thread{}, fmt.Errorf("unexpected thread ID: %w", err)
+	}
 
-	// Converts data format to JSON
-	jsonData, err := json.MarshalIndent(threads, "", "    ")
+	// Comment count processing (string to int)
+	tempCommentCount := strings.TrimSpace(item.Find("span.post-count").Text())
+	tempCommentCount = strings.ReplaceAll(tempCommentCount, "\t\t\t\t\t\t\t\t\t\t\t\t\t", " ")
+	commentNum, err := strconv.Atoi(strings.Split(tempCommentCount, " ")[0])
 	if err != nil {
-		log.Fatalf("Error: %v", err)
+		return Thread{}, fmt.Errorf("unexpected comment count for thread %d: %w", tempID, err)
+	}
+
+	thread := Thread{
+		ID:               tempID,
+		Title:            strings.TrimSpace(item.Find(".thread-item-header-title").Text()),
+		ThreadURL:        base_url + item.Find(".thread-item-header-title").AttrOr("href", ""),
+		FragCount:        tempFragCount,
+		DatePublished:    strings.TrimSpace(item.Find("span.date-full.hide").Text()),
+		DatePublishedAgo: strings.TrimSpace(item.Find("span.js-date-toggle.date-eta").Text()),
+		CommentCount:     commentNum,
 	}
+	thread.PublishedAt = publishedAt(thread.DatePublished, thread.DatePublishedAgo, now)
+	return thread, nil
+}
+
+// Layouts of a thread's full date, e.g. "Nov 6, 2024 at 3:14 PM".
+var publishedLayouts = []string{"Jan 2, 2006 at 3:04 PM", "January 2, 2006 at 3:04 PM"}
+
+// Units of a thread's age, e.g. "2h" or "3d ago". Months and years are approximate.
+var ageUnits = map[string]time.Duration{
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"mo": 30 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
 
-	fmt.Println("Thread scrape complete.")
+// Returns when a thread was posted in UTC from its full date in the source time zone, or, failing that, its age
+// relative to now, rounded down to its unit so that rescraping doesn't move it. Ages of days and longer are only
+// rounded to the hour, as whole days, weeks, months or years since the zero time don't line up with the calendar.
+// Returns nil when neither can be read.
+func publishedAt(date string, ago string, now time.Time) *time.Time {
+	for _, layout := range publishedLayouts {
+		if published, err := time.ParseInLocation(layout, date, sourceLocation); err == nil {
+			published = published.UTC()
+			return &published
+		}
+	}
 
-	return jsonData
+	ago = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(ago), "ago"))
+	digits := strings.IndexFunc(ago, func(r rune) bool { return r < '0' || r > '9' })
+	if digits <= 0 {
+		return nil
+	}
+	count, err := strconv.Atoi(ago[:digits])
+	unit, ok := ageUnits[strings.TrimSpace(ago[digits:])]
+	if err != nil || !ok {
+		return nil
+	}
+	published := now.Add(-time.Duration(count) * unit).UTC().Truncate(min(unit, time.Hour))
+	return &published
+}
+
+// Layout of the data-utc-ts attribute of vlr.gg's moment-tz-convert elements, which hold UTC times.
+const utcTimestampLayout = "2006-01-02 15:04:05"
+
+// Retrieves the start time of a match from its match page, or nil when the page has no data-utc-ts, e.g. while the
+// time is still to be decided.
+func scheduledAtScrape(doc *goquery.Document) *time.Time {
+	value, ok := doc.Find("div.moment-tz-convert[data-utc-ts]").First().Attr("data-utc-ts")
+	if !ok {
+		return nil
+	}
+	scheduledAt, err := time.ParseInLocation(utcTimestampLayout, strings.TrimSpace(value), time.UTC)
+	if err != nil {
+		return nil
+	}
+	return &scheduledAt
 }
 
 // Retrieves match dates for matchScrape
@@ -162,73 +253,137 @@ func dateScrape(doc *goquery.Document) string {
 	return currentDate
 }
 
-// Scrape matches from vlr.gg/matches
-func matchScrape(doc *goquery.Document) []byte {
+// Parses a single match of the vlr.gg/matches listing without visiting its match page, so Date is left empty.
+func parseMatchItem(item *goquery.Selection) (Match, error) {
 
-	var matches []Match
+	// Retrieve ID from URL through string parsing
+	tempID := item.AttrOr("href", "")
+	hrefParts := strings.Split(tempID, "/")
+	if len(hrefParts) < 2 {
+		return Match{}, fmt.Errorf("unexpected match URL %q", tempID)
+	}
+	intTempID, err := strconv.Atoi(hrefParts[1])
+	if err != nil {
+		return Match{}, err
+	}
 
-	doc.Find("a[class*='mod-color']").Each(func(index int, item *goquery.Selection) {
+	// Retrieve team names and trim spaces & \t
+	tempTeam := strings.TrimSpace(item.Find("div.match-item-vs-team").Text())
+	tempTeam = strings.ReplaceAll(tempTeam, "\t", "")
 
-		// Retrieve ID from URL through string parsing
-		tempID := item.AttrOr("href", "")
-		tempID = strings.Split(tempID, "/")[1]
-		intTempID, err := strconv.Atoi(tempID)
-		if err != nil {
-			log.Fatalf("Error: %v", err)
-		}
+	// Assigns team names to team1 and team2
+	teamParts := strings.Split(tempTeam, "\n\n\n")
+	if len(teamParts) < 4 {
+		return Match{}, fmt.Errorf("unexpected team layout for match %d", intTempID)
+	}
+	tempTeam1 := teamParts[0]
+	tempTeam2 := teamParts[3]
+
+	// Checks if team2's name is team1's score. If so, then the team name is the second element.
+	if scrapetools.IsInt(tempTeam2) {
+		tempTeam2 = teamParts[2]
+		tempTeam2 = strings.ReplaceAll(tempTeam2, "\n", "")
+	}
 
-		// Retrieve team names and trim spaces & \t
-		tempTeam := strings.TrimSpace(item.Find("div.match-item-vs-team").Text())
-		tempTeam = strings.ReplaceAll(tempTeam, "\t", "")
+	// Scores are only filled in once a match has started, otherwise they show a dash.
+	var scores []string
+	item.Find("div.match-item-vs-team-score").Each(func(index int, score *goquery.Selection) {
+		scores = append(scores, strings.TrimSpace(score.Text()))
+	})
+	var tempScore1, tempScore2 string
+	if len(scores) == 2 && scrapetools.IsInt(scores[0]) && scrapetools.IsInt(scores[1]) {
+		tempScore1, tempScore2 = scores[0], scores[1]
+	}
 
-		// Assigns team names to team1 and team2
-		tempTeam1 := strings.Split(tempTeam, "\n\n\n")[0]
-		tempTeam2 := strings.Split(tempTeam, "\n\n\n")[3]
+	// If no time until match, then it is live
+	tempTimeUntil := strings.TrimSpace(item.Find("div.ml-eta").Text())
+	if tempTimeUntil == "" {
+		tempTimeUntil = "Live"
+	}
 
-		// Checks if team2's name is team1's score. If so, then the team name is the second element.
-		if scrapetools.IsInt(tempTeam2) {
-			tempTeam2 = strings.Split(tempTeam, "\n\n\n")[2]
-			tempTeam2 = strings.ReplaceAll(tempTeam2, "\n", "")
-		}
+	match := Match{
+		Tournament:     strings.ReplaceAll(strings.TrimSpace(item.Find("div.match-item-event-series.text-of").Text()), "–", " "),
+		ID:             intTempID,
+		MatchURL:       base_url + item.AttrOr("href", ""),
+		Team1:          tempTeam1,
+		Team2:          tempTeam2,
+		Score1:         tempScore1,
+		Score2:         tempScore2,
+		MatchTime:      strings.TrimSpace(item.Find("div.match-item-time").Text()),
+		TimeUntilMatch: tempTimeUntil,
+	}
+	return match, nil
+}
 
-		// If no time until match, then it is live
-		tempTimeUntil := strings.TrimSpace(item.Find("div.ml-eta").Text())
-		if tempTimeUntil == "" {
-			tempTimeUntil = "Live"
-		}
+// Scrape matches from vlr.gg/matches
+func matchScrape(logger *slog.Logger, doc *goquery.Document) ([]byte, error) {
 
-		// Retrieve current match URL for date scraping
-		matchURL := base_url + item.AttrOr("href", "")
+	var matches []Match
+
+	doc.Find("a[class*='mod-color']").Each(func(index int, item *goquery.Selection) {
+		match, err := parseMatchItem(item)
+		if err != nil {
+			skipItem(logger, "matches", err)
+			return
+		}
 
 		// For each match, got to the match page, and retrieve the match date at the top right.
-		dateDoc := ScrapePrep(matchURL)
-
-		match := Match{
-			Tournament:     strings.ReplaceAll(strings.TrimSpace(item.Find("div.match-item-event-series.text-of").Text()), "–", " "),
-			ID:             intTempID,
-			MatchURL:       matchURL,
-			Team1:          tempTeam1,
-			Team2:          tempTeam2,
-			Date:           dateScrape(dateDoc),
-			MatchTime:      strings.TrimSpace(item.Find("div.match-item-time").Text()),
-			TimeUntilMatch: tempTimeUntil,
+		// The match is still listed without its date when the page can't be fetched.
+		matchLogger := logger.With("match_url", match.MatchURL)
+		if dateDoc, err := ScrapePrep(matchLogger, match.MatchURL); err != nil {
+			matchLogger.Warn("fetching match page failed, keeping the match without its date", logging.Err(err))
+		} else {
+			match.Date = dateScrape(dateDoc)
+			match.ScheduledAt = scheduledAtScrape(dateDoc)
 		}
+
 		matches = append(matches, match)
+		metrics.ItemsParsed.WithLabelValues("matches").Inc()
 	})
 
 	// Converts data format to JSON
 	jsonData, err := json.MarshalIndent(matches, "", "    ")
 	if err != nil {
-		log.Fatalf("Error: %v", err)
+		return nil, fmt.Errorf("encoding matches: %w", err)
 	}
 
-	fmt.Println("Match scrape complete.")
+	logger.Info("match page scraped", "items", len(matches))
 
-	return jsonData
+	return jsonData, nil
+}
+
+// Scrapes the first page of vlr.gg/matches at section_url and returns only the matches that are currently live.
+// Match pages aren't visited, so Date is left empty and a poll costs a single request.
+func LiveMatchScrape(section_url string) ([]Match, error) {
+	doc, err := fetchDocument("live", section_url)
+	if err != nil {
+		return nil, err
+	}
+
+	live := []Match{}
+	var parseErr error
+	doc.Find("a[class*='mod-color']").EachWithBreak(func(index int, item *goquery.Selection) bool {
+		match, err := parseMatchItem(item)
+		if err != nil {
+			metrics.ParseErrors.WithLabelValues("live").Inc()
+			parseErr = err
+			return false
+		}
+		metrics.ItemsParsed.WithLabelValues("live").Inc()
+		if match.TimeUntilMatch == "Live" {
+			live = append(live, match)
+		}
+		return true
+	})
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	return live, nil
 }
 
 // Scrape leaderboard rankings and team info from vlr.gg/rankings
-func rankingScrape(doc *goquery.Document, region string) {
+func rankingScrape(logger *slog.Logger, doc *goquery.Document, region string, out sink.Sink, outputDir string) error {
 
 	var rankings []Ranking
 
@@ -236,12 +391,14 @@ func rankingScrape(doc *goquery.Document, region string) {
 
 		tempRank, err := strconv.Atoi(strings.TrimSpace(item.Find("div.rank-item-rank-num").Text()))
 		if err != nil {
-			log.Fatalf("Error: %v", err)
+			skipItem(logger, "rankings", fmt.Errorf("unexpected rank: %w", err))
+			return
 		}
 
 		tempELO, err := strconv.Atoi(item.Find("div.rank-item-rating").AttrOr("data-sort-value", ""))
 		if err != nil {
-			log.Fatalf("Error: %v", err)
+			skipItem(logger, "rankings", fmt.Errorf("unexpected rating: %w", err))
+			return
 		}
 
 		ranking := Ranking{
@@ -253,58 +410,76 @@ func rankingScrape(doc *goquery.Document, region string) {
 		}
 
 		rankings = append(rankings, ranking)
+		metrics.ItemsParsed.WithLabelValues("rankings").Inc()
 	})
 
 	// Converts data format to JSON
 	jsonData, err := json.MarshalIndent(rankings, "", "    ")
 	if err != nil {
-		log.Fatalf("Error: %v", err)
+		return fmt.Errorf("encoding %s rankings: %w", region, err)
 	}
 
 	// Writes JSON data into new/existing JSON file.
-	os.WriteFile("output/ranking/output"+region+"Rankings"+".json", jsonData, 0644)
+	if err := out.Write(context.Background(), path.Join(outputDir, "output"+region+"Rankings"+".json"), jsonData); err != nil {
+		return fmt.Errorf("writing %s rankings: %w", region, err)
+	}
+	logger.Info("region rankings scraped", "items", len(rankings))
+	return nil
 }
 
 // Scrapes the rankings from all regions by using the rankingScrape for each region.
-func AllRankingScrape(doc *goquery.Document) {
+// Each region's page is found under section_url and its rankings are written to outputDir within out.
+// A region that fails doesn't stop the others; the failures are returned together.
+func AllRankingScrape(logger *slog.Logger, doc *goquery.Document, section_url string, out sink.Sink, outputDir string) error {
+	var errs []error
 	doc.Find("a.wf-nav-item.mod-collapsible").Each(func(index int, item *goquery.Selection) {
 
 		// Retrieve the region name and filter out any unnecessary characters.
 		region := strings.TrimSpace(item.Find("span.normal").Text())
 		region = scrapetools.Filter(region, " ", "-")
 
-		fmt.Println(region)
-
 		// Use rankingScrape at the region URL.
 		if region != "World" && region != "" {
-			rankingURL := base_url + "/rankings/" + region
-			rankingDoc := ScrapePrep(rankingURL)
-			rankingScrape(rankingDoc, region)
+			rankingURL := section_url + "/" + region
+			regionLogger := logger.With("region", region, "url", rankingURL)
+			rankingDoc, err := ScrapePrep(regionLogger, rankingURL)
+			if err == nil {
+				err = rankingScrape(regionLogger, rankingDoc, region, out, outputDir)
+			}
+			if err != nil {
+				regionLogger.Error("region ranking scrape failed", logging.Err(err))
+				errs = append(errs, err)
+			}
 		}
 	})
 
-	fmt.Println("Ranking scrape complete.")
+	logger.Info("ranking scrape complete", "failed", len(errs))
+	return errors.Join(errs...)
 }
 
 // Retrieves the number of the last page of threads containing unique threads.
 // Only way since pages out of bounds will still contain the top 4 posts.
 // Verify that the doc.Find() location works for future scrapes.
-func findLastPage(doc *goquery.Document) int {
+func findLastPage(doc *goquery.Document) (int, error) {
 	lastPage := ""
 	doc.Find("a.btn.mod-page").Each(func(index int, item *goquery.Selection) {
 		lastPage = item.Text()
 	})
 	lastPageInt, err := strconv.Atoi(lastPage)
 	if err != nil {
-		log.Fatalf("Error: %v", err)
+		return 0, fmt.Errorf("finding the last page: %w", err)
 	}
 
-	return lastPageInt
+	return lastPageInt, nil
 }
 
 // Conducts scraping for the total number of pages available to the given section_url.
 // For every new scrape added, the switch statement must be edited to cover it.
-func PageParser(section_url string, header string, outputFileName string, paginator *paginator.Paginator) {
+// The combined output is written to out as outputFileName_<timestamp>.json.
+// Every page is logged with its number and URL on top of the fields logger already carries.
+// Pages that fail are left out of the output and their errors returned together; nothing is written when the
+// first page can't be read.
+func PageParser(logger *slog.Logger, section_url string, header string, out sink.Sink, outputFileName string, paginator *paginator.Paginator) error {
 
 	// Stores page of scraped data per index
 	var totalScrape = [][]byte{}
@@ -315,54 +490,65 @@ func PageParser(section_url string, header string, outputFileName string, pagina
 	pageExists := true
 
 	// The class that gives the last page changes when scraping the last page. So before looping, it must be retrieved.
-	prepDocument := ScrapePrep(section_url + header)
-	lastPage := findLastPage(prepDocument)
+	firstURL := section_url + header
+	prepLogger := logger.With("url", firstURL)
+	prepDocument, err := ScrapePrep(prepLogger, firstURL)
+	if err != nil {
+		return err
+	}
+	lastPage, err := findLastPage(prepDocument)
+	if err != nil {
+		return fmt.Errorf("%s: %w", firstURL, err)
+	}
+	logger.Info("section scrape started", "pages", lastPage)
+
+	var errs []error
 
 	// For every page, scrape the data and append it to the totalScrape slice.
 	for pageExists {
 		if currentPage <= lastPage {
 			url := fmt.Sprintf("%s%s&page=%d", section_url, header, currentPage)
+			pageLogger := logger.With("page", currentPage, "url", url)
 
 			// Wait for permission from the limiter
-			if err := paginator.Limiter.Wait(paginator.Context); err != nil {
-				fmt.Println("Rate limiter context canceled or timed out:", err)
+			if err := paginator.Wait(sectionOf(section_url)); err != nil {
+				pageLogger.Warn("rate limiter canceled or timed out, keeping the pages scraped so far", logging.Err(err))
 				break
 			}
 
-			document := ScrapePrep(url)
-
-			switch section_url {
-			case "https://www.vlr.gg/threads":
-				currentPageScrape = threadScrape(currentPage, document)
-			case "https://www.vlr.gg/matches":
-				currentPageScrape = matchScrape(document)
-			default:
-				fmt.Println("Invalid base URL.")
+			document, err := ScrapePrep(pageLogger, url)
+			if err == nil {
+				switch section_url {
+				case base_url + "/threads":
+					currentPageScrape, err = threadScrape(pageLogger, currentPage, document)
+				case base_url + "/matches":
+					currentPageScrape, err = matchScrape(pageLogger, document)
+				default:
+					err = fmt.Errorf("no scraper for %s", section_url)
+				}
+			}
+			currentPage++
+			if err != nil {
+				pageLogger.Error("page scrape failed, leaving it out", logging.Err(err))
+				errs = append(errs, err)
+				continue
 			}
 
 			// Join all pages of data.
 			totalScrape = append(totalScrape, currentPageScrape)
-			currentPage++
 
 		} else {
 			pageExists = false
 		}
 	}
 
-	// Create the output file.
-	timeStamp := time.Now().Format("2006-01-02_15-04-05")
-	file, err := os.Create("output/" + outputFileName + "_" + timeStamp + ".json")
-	if err != nil {
-		log.Fatal(err)
+	// Join the pages into one JSON array and write it to every sink.
+	// Writes aren't tied to the shutdown signal, so a scrape that has finished isn't lost on the way out.
+	timeStamp := time.Now().Format(TimestampLayout)
+	outputName := outputFileName + "_" + timeStamp + ".json"
+	if err := out.Write(context.Background(), outputName, scrapetools.JoinPages(bytes.Join(totalScrape, nil))); err != nil {
+		return errors.Join(append(errs, fmt.Errorf("writing %s: %w", outputName, err))...)
 	}
-	defer file.Close()
-
-	// Write the JSON data to a file.
-	for i := 0; i < len(totalScrape); i++ {
-		if _, err := file.Write(totalScrape[i]); err != nil {
-			log.Fatal(err)
-		}
-	}
-
-	scrapetools.FileFix("output/" + outputFileName + "_" + timeStamp + ".json")
+	logger.Info("section scrape complete", "pages", len(totalScrape), "failed", len(errs), "name", outputName)
+	return errors.Join(errs...)
 }
@@ -0,0 +1,51 @@
+package scrape
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishedAt(t *testing.T) {
+	now := time.Date(2024, 11, 7, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name string
+		date string
+		ago  string
+		want string // RFC 3339, empty for nil.
+	}{
+		{name: "full date", date: "Nov 6, 2024 at 3:14 PM", ago: "1d", want: "2024-11-06T15:14:00Z"},
+		{name: "full month", date: "November 6, 2024 at 3:14 PM", want: "2024-11-06T15:14:00Z"},
+		{name: "seconds", ago: "30s", want: "2024-11-07T15:03:35Z"},
+		{name: "minutes", ago: "12m ago", want: "2024-11-07T14:52:00Z"},
+		{name: "hours", ago: "2h", want: "2024-11-07T13:00:00Z"},
+		{name: "days", ago: " 3d ago ", want: "2024-11-04T15:00:00Z"},
+		{name: "weeks", ago: "2w", want: "2024-10-24T15:00:00Z"},
+		{name: "months", ago: "1mo", want: "2024-10-08T15:00:00Z"},
+		{name: "years", ago: "1y ago", want: "2023-11-08T15:00:00Z"},
+		{name: "unreadable date falls back to age", date: "yesterday", ago: "5h", want: "2024-11-07T10:00:00Z"},
+		{name: "unknown unit", ago: "2x"},
+		{name: "no count", ago: "h ago"},
+		{name: "empty"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ""
+			if published := publishedAt(test.date, test.ago, now); published != nil {
+				got = published.Format(time.RFC3339)
+			}
+			if got != test.want {
+				t.Errorf("publishedAt(%q, %q) = %q, want %q", test.date, test.ago, got, test.want)
+			}
+		})
+	}
+}
+
+func TestPublishedAtSourceLocation(t *testing.T) {
+	defer SetSourceLocation(sourceLocation)
+	SetSourceLocation(time.FixedZone("EST", -5*3600))
+
+	published := publishedAt("Nov 6, 2024 at 3:14 PM", "", time.Now())
+	if published == nil || !published.Equal(time.Date(2024, 11, 6, 20, 14, 0, 0, time.UTC)) || published.Location() != time.UTC {
+		t.Errorf("publishedAt = %v, want 2024-11-06 20:14 UTC", published)
+	}
+}
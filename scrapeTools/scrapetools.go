@@ -1,7 +1,6 @@
 package scrapetools
 
 import (
-	"log"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -26,32 +25,29 @@ func Filter(input string, filterValue string, replaceValue string) string {
 }
 
 // Properly joins all of the JSON files into one via handling excessive brackets.
-func FileFix(fileName string) {
+func FileFix(fileName string) error {
 	file, err := os.ReadFile(fileName)
 	if err != nil {
-		log.Fatalf("Error: %v", err)
+		return err
 	}
 
-	allText := string(file)
-	allText = strings.ReplaceAll(allText, "    }\n][\n    {", "    },\n    {")
+	return os.WriteFile(fileName, JoinPages(file), 0644)
+}
 
-	err = os.WriteFile(fileName, []byte(allText), 0644)
-	if err != nil {
-		log.Fatalf("Error: %v", err)
-	}
+// Joins the JSON arrays of consecutive pages, as written back to back, into a single array.
+func JoinPages(data []byte) []byte {
+	allText := string(data)
+	allText = strings.ReplaceAll(allText, "    }\n][\n    {", "    },\n    {")
+	return []byte(allText)
 }
 
 // Creates output folder to store JSON files
-func CreateDirectory(folderName string) {
+func CreateDirectory(folderName string) error {
 	outputPath, err := os.Getwd()
 	if err != nil {
-		log.Fatalf("Error: %v", err)
+		return err
 	}
 	// Create the "output" directory
 	outputDir := filepath.Join(outputPath, folderName)
-	err = os.MkdirAll(outputDir, 0755) // Creates directory if it doesn't exist
-	if err != nil {
-		log.Fatalf("Error: %v", err)
-	}
-
+	return os.MkdirAll(outputDir, 0755) // Creates directory if it doesn't exist
 }
@@ -0,0 +1,50 @@
+package sink
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// Writes output files under a directory on the local filesystem, where the REST and gRPC APIs read them.
+type Local struct {
+	dir string
+}
+
+func NewLocal(dir string) *Local {
+	return &Local{dir: dir}
+}
+
+func (local *Local) Name() string {
+	return "local " + local.dir
+}
+
+// Writes through a temporary file renamed into place, so the APIs never read a half-written snapshot.
+func (local *Local) Write(ctx context.Context, name string, data []byte) error {
+	path := filepath.Join(local.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(temp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+func (local *Local) Close() error {
+	return nil
+}
@@ -0,0 +1,81 @@
+package sink
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalWrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // Content already at the path, if any.
+		file     string
+		data     string
+	}{
+		{"new file", "", "outputThreads_2024-11-06_15-04-05.json", `[{"id": 1}]`},
+		{"replaces", `[{"id": 1}]`, "outputThreads_2024-11-06_15-04-05.json", `[{"id": 2}]`},
+		{"nested directory", "", "ranking/outputEuropeRankings.json", `[]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, filepath.FromSlash(test.file))
+			if test.existing != "" {
+				if err := os.WriteFile(path, []byte(test.existing), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := NewLocal(dir).Write(context.Background(), test.file, []byte(test.data)); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.data {
+				t.Errorf("file holds %q, want %q", got, test.data)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0644 {
+				t.Errorf("file mode = %v, want 0644", info.Mode().Perm())
+			}
+			assertNoTempFiles(t, filepath.Dir(path))
+		})
+	}
+}
+
+// A write that can't be renamed into place leaves what was there and no temporary file behind.
+func TestLocalWriteFailureKeepsPrevious(t *testing.T) {
+	dir := t.TempDir()
+	// A non-empty directory where the file should go can't be replaced by a rename.
+	blocked := filepath.Join(dir, "outputThreads_2024-11-06_15-04-05.json")
+	if err := os.MkdirAll(filepath.Join(blocked, "child"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewLocal(dir).Write(context.Background(), "outputThreads_2024-11-06_15-04-05.json", []byte("[]")); err == nil {
+		t.Fatal("Write over a non-empty directory succeeded")
+	}
+	if info, err := os.Stat(blocked); err != nil || !info.IsDir() {
+		t.Errorf("the directory in the way was replaced: %v", err)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, ".tmp-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) > 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
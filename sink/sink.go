@@ -0,0 +1,105 @@
+// Package sink defines where scraped output goes. Scrapers write each finished file once to a Sink,
+// and Multi passes it on to every configured destination.
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// A destination for scraped output files.
+type Sink interface {
+	// Short description used in logs and errors, e.g. "local output" or "s3 my-bucket".
+	Name() string
+	// Stores one output file, replacing any earlier file of the same name. name is slash-separated and relative
+	// to the output root, e.g. "outputThreads_2024-11-07_15-04-05.json" or "ranking/outputEuropeRankings.json".
+	Write(ctx context.Context, name string, data []byte) error
+	Close() error
+}
+
+// Implemented by sinks that keep track of what one scrape run wrote, e.g. to catch two files stored under the same
+// name. BeginRun is called before every run.
+type RunTracker interface {
+	BeginRun()
+}
+
+// Passes every write on to Sink and remembers the names written since the last BeginRun, whether or not the sink
+// took them, so that a run can tell which sections produced output.
+type Recorder struct {
+	Sink
+	mu      sync.Mutex
+	written []string
+}
+
+func (recorder *Recorder) Write(ctx context.Context, name string, data []byte) error {
+	recorder.mu.Lock()
+	recorder.written = append(recorder.written, name)
+	recorder.mu.Unlock()
+	return recorder.Sink.Write(ctx, name, data)
+}
+
+// Forgets the names written so far and starts a new run on Sink if it tracks runs.
+func (recorder *Recorder) BeginRun() {
+	recorder.mu.Lock()
+	recorder.written = nil
+	recorder.mu.Unlock()
+	if tracker, ok := recorder.Sink.(RunTracker); ok {
+		tracker.BeginRun()
+	}
+}
+
+// Returns the names written since the last BeginRun.
+func (recorder *Recorder) Written() []string {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return slices.Clone(recorder.written)
+}
+
+// Writes to several sinks at once.
+type Multi []Sink
+
+func (sinks Multi) Name() string {
+	name := "multi"
+	for i, sink := range sinks {
+		separator := ", "
+		if i == 0 {
+			separator = ": "
+		}
+		name += separator + sink.Name()
+	}
+	return name
+}
+
+// Writes to every sink even when some fail, so one unreachable destination doesn't cost the others their copy.
+// The errors of the failed sinks are joined.
+func (sinks Multi) Write(ctx context.Context, name string, data []byte) error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Write(ctx, name, data); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Starts a new run on every sink that tracks runs.
+func (sinks Multi) BeginRun() {
+	for _, sink := range sinks {
+		if tracker, ok := sink.(RunTracker); ok {
+			tracker.BeginRun()
+		}
+	}
+}
+
+func (sinks Multi) Close() error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,109 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Records what it was given and fails with err when set.
+type fakeSink struct {
+	name    string
+	err     error
+	written []string
+	closed  bool
+}
+
+func (fake *fakeSink) Name() string { return fake.name }
+
+func (fake *fakeSink) Write(ctx context.Context, name string, data []byte) error {
+	fake.written = append(fake.written, name)
+	return fake.err
+}
+
+func (fake *fakeSink) Close() error {
+	fake.closed = true
+	return fake.err
+}
+
+func TestMulti(t *testing.T) {
+	errDown := errors.New("down")
+	errFull := errors.New("full")
+
+	tests := []struct {
+		name    string
+		errs    []error // One sink per entry.
+		wantErr []error
+		message []string
+	}{
+		{"no sinks", nil, nil, nil},
+		{"all succeed", []error{nil, nil}, nil, nil},
+		{"one fails", []error{nil, errDown}, []error{errDown}, []string{"b: down"}},
+		{"all fail", []error{errDown, errFull}, []error{errDown, errFull}, []string{"a: down", "b: full"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sinks Multi
+			for i, err := range test.errs {
+				sinks = append(sinks, &fakeSink{name: string(rune('a' + i)), err: err})
+			}
+
+			for _, check := range []struct {
+				method string
+				err    error
+			}{
+				{"Write", sinks.Write(context.Background(), "file.json", []byte("[]"))},
+				{"Close", sinks.Close()},
+			} {
+				if (check.err != nil) != (len(test.wantErr) > 0) {
+					t.Fatalf("%s returned %v, want errors %v", check.method, check.err, test.wantErr)
+				}
+				for _, want := range test.wantErr {
+					if !errors.Is(check.err, want) {
+						t.Errorf("%s returned %v, which doesn't wrap %v", check.method, check.err, want)
+					}
+				}
+				for _, message := range test.message {
+					if !strings.Contains(check.err.Error(), message) {
+						t.Errorf("%s returned %q, want it to mention %q", check.method, check.err, message)
+					}
+				}
+			}
+
+			// A failing sink doesn't keep the others from their copy.
+			for _, sink := range sinks {
+				fake := sink.(*fakeSink)
+				if len(fake.written) != 1 || !fake.closed {
+					t.Errorf("sink %s got writes %v and closed %v, want one write and a close", fake.name, fake.written, fake.closed)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiName(t *testing.T) {
+	sinks := Multi{&fakeSink{name: "local out"}, &fakeSink{name: "stdout"}}
+	if got, want := sinks.Name(), "multi: local out, stdout"; got != want {
+		t.Errorf("Name() = %q, want %q", got, want)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	fake := &fakeSink{name: "fake", err: errors.New("down")}
+	recorder := &Recorder{Sink: Multi{fake}}
+
+	recorder.Write(context.Background(), "outputThreads_2024-11-06_15-04-05.json", nil)
+	recorder.Write(context.Background(), "ranking/outputEuropeRankings.json", nil)
+	if written := recorder.Written(); len(written) != 2 || written[1] != "ranking/outputEuropeRankings.json" {
+		t.Errorf("Written() = %v, want both files even though the sink failed", written)
+	}
+
+	recorder.BeginRun()
+	if written := recorder.Written(); len(written) != 0 {
+		t.Errorf("Written() = %v after BeginRun, want nothing", written)
+	}
+	if len(fake.written) != 2 {
+		t.Errorf("the sink was given %v, want every write", fake.written)
+	}
+}
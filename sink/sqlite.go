@@ -0,0 +1,51 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Stores output files as rows of a files table in a SQLite database, one row per name.
+// The JSON can be queried in place, e.g. SELECT value FROM files, json_each(files.data) WHERE name LIKE 'outputThreads_%'.
+type SQLite struct {
+	path string
+	db   *sql.DB
+}
+
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS files (
+	name       TEXT PRIMARY KEY,
+	written_at TEXT NOT NULL,
+	data       TEXT NOT NULL
+)`
+
+// Opens the database at path, creating it and its table if needed.
+func NewSQLite(path string) (*SQLite, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLite{path: path, db: db}, nil
+}
+
+func (sqlite *SQLite) Name() string {
+	return "sqlite " + sqlite.path
+}
+
+func (sqlite *SQLite) Write(ctx context.Context, name string, data []byte) error {
+	_, err := sqlite.db.ExecContext(ctx,
+		`INSERT INTO files (name, written_at, data) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET written_at = excluded.written_at, data = excluded.data`,
+		name, time.Now().UTC().Format(time.RFC3339), string(data))
+	return err
+}
+
+func (sqlite *SQLite) Close() error {
+	return sqlite.db.Close()
+}
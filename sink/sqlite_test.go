@@ -0,0 +1,42 @@
+package sink
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// Rewriting a name replaces its row, and the stored JSON can be queried with SQLite's json functions.
+func TestSQLiteWrite(t *testing.T) {
+	db, err := NewSQLite(filepath.Join(t.TempDir(), "vlrscrape.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	writes := []struct {
+		name string
+		data string
+	}{
+		{"outputThreads_2024-11-06_15-04-05.json", `[{"id": 1}]`},
+		{"outputThreads_2024-11-06_15-04-05.json", `[{"id": 1}, {"id": 2}]`},
+		{"ranking/outputEuropeRankings.json", `[{"rank": 1}]`},
+	}
+	for _, write := range writes {
+		if err := db.Write(ctx, write.name, []byte(write.data)); err != nil {
+			t.Fatalf("Write %s: %v", write.name, err)
+		}
+	}
+
+	var files, threads int
+	if err := db.db.QueryRowContext(ctx, `SELECT count(*) FROM files`).Scan(&files); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.db.QueryRowContext(ctx, `SELECT count(*) FROM files, json_each(files.data) WHERE name LIKE 'outputThreads_%'`).Scan(&threads); err != nil {
+		t.Fatal(err)
+	}
+	if files != 2 || threads != 2 {
+		t.Errorf("got %d files holding %d threads, want 2 files holding 2 threads", files, threads)
+	}
+}
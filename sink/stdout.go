@@ -0,0 +1,46 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Writes each output file to w as one line of JSON, {"name": "...", "data": [...]}, for piping into other tools.
+type Stdout struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewStdout(w io.Writer) *Stdout {
+	return &Stdout{w: w}
+}
+
+func (stdout *Stdout) Name() string {
+	return "stdout"
+}
+
+func (stdout *Stdout) Write(ctx context.Context, name string, data []byte) error {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return err
+	}
+	line, err := json.Marshal(struct {
+		Name string          `json:"name"`
+		Data json.RawMessage `json:"data"`
+	}{name, compact.Bytes()})
+	if err != nil {
+		return err
+	}
+
+	stdout.mu.Lock()
+	defer stdout.mu.Unlock()
+	_, err = stdout.w.Write(append(line, '\n'))
+	return err
+}
+
+func (stdout *Stdout) Close() error {
+	return nil
+}
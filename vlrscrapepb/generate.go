@@ -0,0 +1,5 @@
+// Package vlrscrapepb holds the protobuf definitions of the gRPC API and the Go stubs generated from them.
+// Other languages can generate their own stubs from vlrscrape.proto.
+package vlrscrapepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative vlrscrape.proto
@@ -0,0 +1,1320 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: vlrscrape.proto
+
+package vlrscrapepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MatchEvent_Type int32
+
+const (
+	MatchEvent_TYPE_UNSPECIFIED MatchEvent_Type = 0
+	// Every match live at the time, sent to streams that can't resume.
+	MatchEvent_TYPE_SNAPSHOT MatchEvent_Type = 1
+	// A match has gone live.
+	MatchEvent_TYPE_LIVE MatchEvent_Type = 2
+	// The score of a live match has changed.
+	MatchEvent_TYPE_SCORE MatchEvent_Type = 3
+	// A match is no longer live. Carries its last known state.
+	MatchEvent_TYPE_FINISHED MatchEvent_Type = 4
+)
+
+// Enum value maps for MatchEvent_Type.
+var (
+	MatchEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_SNAPSHOT",
+		2: "TYPE_LIVE",
+		3: "TYPE_SCORE",
+		4: "TYPE_FINISHED",
+	}
+	MatchEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_SNAPSHOT":    1,
+		"TYPE_LIVE":        2,
+		"TYPE_SCORE":       3,
+		"TYPE_FINISHED":    4,
+	}
+)
+
+func (x MatchEvent_Type) Enum() *MatchEvent_Type {
+	p := new(MatchEvent_Type)
+	*p = x
+	return p
+}
+
+func (x MatchEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MatchEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_vlrscrape_proto_enumTypes[0].Descriptor()
+}
+
+func (MatchEvent_Type) Type() protoreflect.EnumType {
+	return &file_vlrscrape_proto_enumTypes[0]
+}
+
+func (x MatchEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MatchEvent_Type.Descriptor instead.
+func (MatchEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{14, 0}
+}
+
+type Thread struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title            string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	ThreadUrl        string                 `protobuf:"bytes,3,opt,name=thread_url,json=threadUrl,proto3" json:"thread_url,omitempty"`
+	FragCount        int32                  `protobuf:"varint,4,opt,name=frag_count,json=fragCount,proto3" json:"frag_count,omitempty"`
+	DatePublished    string                 `protobuf:"bytes,5,opt,name=date_published,json=datePublished,proto3" json:"date_published,omitempty"`
+	DatePublishedAgo string                 `protobuf:"bytes,6,opt,name=date_published_ago,json=datePublishedAgo,proto3" json:"date_published_ago,omitempty"`
+	CommentCount     int32                  `protobuf:"varint,7,opt,name=comment_count,json=commentCount,proto3" json:"comment_count,omitempty"`
+	// Posting time in UTC, unset when vlr.gg's dates can't be read.
+	PublishedAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	// Derived from published_at when served; unset along with it.
+	AgeHours        *float64 `protobuf:"fixed64,9,opt,name=age_hours,json=ageHours,proto3,oneof" json:"age_hours,omitempty"`
+	FragsPerHour    *float64 `protobuf:"fixed64,10,opt,name=frags_per_hour,json=fragsPerHour,proto3,oneof" json:"frags_per_hour,omitempty"`
+	CommentsPerHour *float64 `protobuf:"fixed64,11,opt,name=comments_per_hour,json=commentsPerHour,proto3,oneof" json:"comments_per_hour,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Thread) Reset() {
+	*x = Thread{}
+	mi := &file_vlrscrape_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Thread) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Thread) ProtoMessage() {}
+
+func (x *Thread) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Thread.ProtoReflect.Descriptor instead.
+func (*Thread) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Thread) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Thread) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Thread) GetThreadUrl() string {
+	if x != nil {
+		return x.ThreadUrl
+	}
+	return ""
+}
+
+func (x *Thread) GetFragCount() int32 {
+	if x != nil {
+		return x.FragCount
+	}
+	return 0
+}
+
+func (x *Thread) GetDatePublished() string {
+	if x != nil {
+		return x.DatePublished
+	}
+	return ""
+}
+
+func (x *Thread) GetDatePublishedAgo() string {
+	if x != nil {
+		return x.DatePublishedAgo
+	}
+	return ""
+}
+
+func (x *Thread) GetCommentCount() int32 {
+	if x != nil {
+		return x.CommentCount
+	}
+	return 0
+}
+
+func (x *Thread) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return nil
+}
+
+func (x *Thread) GetAgeHours() float64 {
+	if x != nil && x.AgeHours != nil {
+		return *x.AgeHours
+	}
+	return 0
+}
+
+func (x *Thread) GetFragsPerHour() float64 {
+	if x != nil && x.FragsPerHour != nil {
+		return *x.FragsPerHour
+	}
+	return 0
+}
+
+func (x *Thread) GetCommentsPerHour() float64 {
+	if x != nil && x.CommentsPerHour != nil {
+		return *x.CommentsPerHour
+	}
+	return 0
+}
+
+type Match struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	MatchUrl   string                 `protobuf:"bytes,2,opt,name=match_url,json=matchUrl,proto3" json:"match_url,omitempty"`
+	Tournament string                 `protobuf:"bytes,3,opt,name=tournament,proto3" json:"tournament,omitempty"`
+	Team1      string                 `protobuf:"bytes,4,opt,name=team1,proto3" json:"team1,omitempty"`
+	Team2      string                 `protobuf:"bytes,5,opt,name=team2,proto3" json:"team2,omitempty"`
+	// Empty until the match has started.
+	Score1         string `protobuf:"bytes,6,opt,name=score1,proto3" json:"score1,omitempty"`
+	Score2         string `protobuf:"bytes,7,opt,name=score2,proto3" json:"score2,omitempty"`
+	Date           string `protobuf:"bytes,8,opt,name=date,proto3" json:"date,omitempty"`
+	MatchTime      string `protobuf:"bytes,9,opt,name=match_time,json=matchTime,proto3" json:"match_time,omitempty"`
+	TimeUntilMatch string `protobuf:"bytes,10,opt,name=time_until_match,json=timeUntilMatch,proto3" json:"time_until_match,omitempty"`
+	// Start time, unset when vlr.gg doesn't give one. date, match_time and time_until_match are derived from it in
+	// the server's display time zone when it's set.
+	ScheduledAt   *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=scheduled_at,json=scheduledAt,proto3" json:"scheduled_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Match) Reset() {
+	*x = Match{}
+	mi := &file_vlrscrape_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Match) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Match) ProtoMessage() {}
+
+func (x *Match) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Match.ProtoReflect.Descriptor instead.
+func (*Match) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Match) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Match) GetMatchUrl() string {
+	if x != nil {
+		return x.MatchUrl
+	}
+	return ""
+}
+
+func (x *Match) GetTournament() string {
+	if x != nil {
+		return x.Tournament
+	}
+	return ""
+}
+
+func (x *Match) GetTeam1() string {
+	if x != nil {
+		return x.Team1
+	}
+	return ""
+}
+
+func (x *Match) GetTeam2() string {
+	if x != nil {
+		return x.Team2
+	}
+	return ""
+}
+
+func (x *Match) GetScore1() string {
+	if x != nil {
+		return x.Score1
+	}
+	return ""
+}
+
+func (x *Match) GetScore2() string {
+	if x != nil {
+		return x.Score2
+	}
+	return ""
+}
+
+func (x *Match) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *Match) GetMatchTime() string {
+	if x != nil {
+		return x.MatchTime
+	}
+	return ""
+}
+
+func (x *Match) GetTimeUntilMatch() string {
+	if x != nil {
+		return x.TimeUntilMatch
+	}
+	return ""
+}
+
+func (x *Match) GetScheduledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledAt
+	}
+	return nil
+}
+
+type Ranking struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rank          int32                  `protobuf:"varint,1,opt,name=rank,proto3" json:"rank,omitempty"`
+	Region        string                 `protobuf:"bytes,2,opt,name=region,proto3" json:"region,omitempty"`
+	TeamName      string                 `protobuf:"bytes,3,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	Elo           int32                  `protobuf:"varint,4,opt,name=elo,proto3" json:"elo,omitempty"`
+	TeamUrl       string                 `protobuf:"bytes,5,opt,name=team_url,json=teamUrl,proto3" json:"team_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ranking) Reset() {
+	*x = Ranking{}
+	mi := &file_vlrscrape_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ranking) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ranking) ProtoMessage() {}
+
+func (x *Ranking) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ranking.ProtoReflect.Descriptor instead.
+func (*Ranking) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Ranking) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *Ranking) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Ranking) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+func (x *Ranking) GetElo() int32 {
+	if x != nil {
+		return x.Elo
+	}
+	return 0
+}
+
+func (x *Ranking) GetTeamUrl() string {
+	if x != nil {
+		return x.TeamUrl
+	}
+	return ""
+}
+
+// A match with the current ranking of each team, where the team is ranked in a scraped region.
+type MatchDetail struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Match         *Match                 `protobuf:"bytes,1,opt,name=match,proto3" json:"match,omitempty"`
+	Team1Ranking  *Ranking               `protobuf:"bytes,2,opt,name=team1_ranking,json=team1Ranking,proto3" json:"team1_ranking,omitempty"`
+	Team2Ranking  *Ranking               `protobuf:"bytes,3,opt,name=team2_ranking,json=team2Ranking,proto3" json:"team2_ranking,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MatchDetail) Reset() {
+	*x = MatchDetail{}
+	mi := &file_vlrscrape_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MatchDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchDetail) ProtoMessage() {}
+
+func (x *MatchDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchDetail.ProtoReflect.Descriptor instead.
+func (*MatchDetail) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MatchDetail) GetMatch() *Match {
+	if x != nil {
+		return x.Match
+	}
+	return nil
+}
+
+func (x *MatchDetail) GetTeam1Ranking() *Ranking {
+	if x != nil {
+		return x.Team1Ranking
+	}
+	return nil
+}
+
+func (x *MatchDetail) GetTeam2Ranking() *Ranking {
+	if x != nil {
+		return x.Team2Ranking
+	}
+	return nil
+}
+
+// A team assembled from its regional rankings and the matches it appears in.
+type Team struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	TeamUrl       string                 `protobuf:"bytes,3,opt,name=team_url,json=teamUrl,proto3" json:"team_url,omitempty"`
+	Rankings      []*Ranking             `protobuf:"bytes,4,rep,name=rankings,proto3" json:"rankings,omitempty"`
+	Matches       []*Match               `protobuf:"bytes,5,rep,name=matches,proto3" json:"matches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Team) Reset() {
+	*x = Team{}
+	mi := &file_vlrscrape_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Team) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Team) ProtoMessage() {}
+
+func (x *Team) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Team.ProtoReflect.Descriptor instead.
+func (*Team) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Team) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *Team) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Team) GetTeamUrl() string {
+	if x != nil {
+		return x.TeamUrl
+	}
+	return ""
+}
+
+func (x *Team) GetRankings() []*Ranking {
+	if x != nil {
+		return x.Rankings
+	}
+	return nil
+}
+
+func (x *Team) GetMatches() []*Match {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+type GetThreadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetThreadRequest) Reset() {
+	*x = GetThreadRequest{}
+	mi := &file_vlrscrape_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetThreadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetThreadRequest) ProtoMessage() {}
+
+func (x *GetThreadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetThreadRequest.ProtoReflect.Descriptor instead.
+func (*GetThreadRequest) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetThreadRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetMatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMatchRequest) Reset() {
+	*x = GetMatchRequest{}
+	mi := &file_vlrscrape_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMatchRequest) ProtoMessage() {}
+
+func (x *GetMatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMatchRequest.ProtoReflect.Descriptor instead.
+func (*GetMatchRequest) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetMatchRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetTeamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Last segment of the team's vlr.gg URL, e.g. sentinels.
+	Slug          string `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTeamRequest) Reset() {
+	*x = GetTeamRequest{}
+	mi := &file_vlrscrape_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTeamRequest) ProtoMessage() {}
+
+func (x *GetTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTeamRequest.ProtoReflect.Descriptor instead.
+func (*GetTeamRequest) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetTeamRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+// Same semantics as the query parameters of the REST API.
+type ListRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filters of the listed type keyed by name, e.g. {"team": "Sentinels"} or {"min_frags": "10"}.
+	Filters map[string]string `protobuf:"bytes,1,rep,name=filters,proto3" json:"filters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Comma separated fields to sort by, prefixed with - for descending order.
+	Sort string `protobuf:"bytes,2,opt,name=sort,proto3" json:"sort,omitempty"`
+	// Page size between 1 and 500, 50 when unset.
+	Limit int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// next_page_token of the previous response.
+	PageToken     string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	mi := &file_vlrscrape_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListRequest) GetFilters() map[string]string {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+func (x *ListRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *ListRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListRankingsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Region as written on vlr.gg, e.g. Asia-Pacific.
+	Region        string       `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+	Query         *ListRequest `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRankingsRequest) Reset() {
+	*x = ListRankingsRequest{}
+	mi := &file_vlrscrape_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRankingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRankingsRequest) ProtoMessage() {}
+
+func (x *ListRankingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRankingsRequest.ProtoReflect.Descriptor instead.
+func (*ListRankingsRequest) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListRankingsRequest) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ListRankingsRequest) GetQuery() *ListRequest {
+	if x != nil {
+		return x.Query
+	}
+	return nil
+}
+
+type ListThreadsResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Threads []*Thread              `protobuf:"bytes,1,rep,name=threads,proto3" json:"threads,omitempty"`
+	Total   int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListThreadsResponse) Reset() {
+	*x = ListThreadsResponse{}
+	mi := &file_vlrscrape_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListThreadsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListThreadsResponse) ProtoMessage() {}
+
+func (x *ListThreadsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListThreadsResponse.ProtoReflect.Descriptor instead.
+func (*ListThreadsResponse) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListThreadsResponse) GetThreads() []*Thread {
+	if x != nil {
+		return x.Threads
+	}
+	return nil
+}
+
+func (x *ListThreadsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListThreadsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type ListMatchesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Matches       []*Match               `protobuf:"bytes,1,rep,name=matches,proto3" json:"matches,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMatchesResponse) Reset() {
+	*x = ListMatchesResponse{}
+	mi := &file_vlrscrape_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMatchesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMatchesResponse) ProtoMessage() {}
+
+func (x *ListMatchesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMatchesResponse.ProtoReflect.Descriptor instead.
+func (*ListMatchesResponse) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListMatchesResponse) GetMatches() []*Match {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+func (x *ListMatchesResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListMatchesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type ListRankingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rankings      []*Ranking             `protobuf:"bytes,1,rep,name=rankings,proto3" json:"rankings,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRankingsResponse) Reset() {
+	*x = ListRankingsResponse{}
+	mi := &file_vlrscrape_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRankingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRankingsResponse) ProtoMessage() {}
+
+func (x *ListRankingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRankingsResponse.ProtoReflect.Descriptor instead.
+func (*ListRankingsResponse) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListRankingsResponse) GetRankings() []*Ranking {
+	if x != nil {
+		return x.Rankings
+	}
+	return nil
+}
+
+func (x *ListRankingsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListRankingsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type WatchMatchesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID of the last event received, to resume a dropped stream.
+	LastEventId   int64 `protobuf:"varint,1,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchMatchesRequest) Reset() {
+	*x = WatchMatchesRequest{}
+	mi := &file_vlrscrape_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchMatchesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchMatchesRequest) ProtoMessage() {}
+
+func (x *WatchMatchesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchMatchesRequest.ProtoReflect.Descriptor instead.
+func (*WatchMatchesRequest) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WatchMatchesRequest) GetLastEventId() int64 {
+	if x != nil {
+		return x.LastEventId
+	}
+	return 0
+}
+
+type MatchEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type  MatchEvent_Type        `protobuf:"varint,2,opt,name=type,proto3,enum=vlrscrape.v1.MatchEvent_Type" json:"type,omitempty"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	Match *Match                 `protobuf:"bytes,4,opt,name=match,proto3" json:"match,omitempty"`
+	// Only set on snapshot events.
+	Matches       []*Match `protobuf:"bytes,5,rep,name=matches,proto3" json:"matches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MatchEvent) Reset() {
+	*x = MatchEvent{}
+	mi := &file_vlrscrape_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MatchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchEvent) ProtoMessage() {}
+
+func (x *MatchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_vlrscrape_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchEvent.ProtoReflect.Descriptor instead.
+func (*MatchEvent) Descriptor() ([]byte, []int) {
+	return file_vlrscrape_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *MatchEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *MatchEvent) GetType() MatchEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return MatchEvent_TYPE_UNSPECIFIED
+}
+
+func (x *MatchEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *MatchEvent) GetMatch() *Match {
+	if x != nil {
+		return x.Match
+	}
+	return nil
+}
+
+func (x *MatchEvent) GetMatches() []*Match {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+var File_vlrscrape_proto protoreflect.FileDescriptor
+
+const file_vlrscrape_proto_rawDesc = "" +
+	"\n" +
+	"\x0fvlrscrape.proto\x12\fvlrscrape.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xda\x03\n" +
+	"\x06Thread\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x1d\n" +
+	"\n" +
+	"thread_url\x18\x03 \x01(\tR\tthreadUrl\x12\x1d\n" +
+	"\n" +
+	"frag_count\x18\x04 \x01(\x05R\tfragCount\x12%\n" +
+	"\x0edate_published\x18\x05 \x01(\tR\rdatePublished\x12,\n" +
+	"\x12date_published_ago\x18\x06 \x01(\tR\x10datePublishedAgo\x12#\n" +
+	"\rcomment_count\x18\a \x01(\x05R\fcommentCount\x12=\n" +
+	"\fpublished_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vpublishedAt\x12 \n" +
+	"\tage_hours\x18\t \x01(\x01H\x00R\bageHours\x88\x01\x01\x12)\n" +
+	"\x0efrags_per_hour\x18\n" +
+	" \x01(\x01H\x01R\ffragsPerHour\x88\x01\x01\x12/\n" +
+	"\x11comments_per_hour\x18\v \x01(\x01H\x02R\x0fcommentsPerHour\x88\x01\x01B\f\n" +
+	"\n" +
+	"_age_hoursB\x11\n" +
+	"\x0f_frags_per_hourB\x14\n" +
+	"\x12_comments_per_hour\"\xcc\x02\n" +
+	"\x05Match\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1b\n" +
+	"\tmatch_url\x18\x02 \x01(\tR\bmatchUrl\x12\x1e\n" +
+	"\n" +
+	"tournament\x18\x03 \x01(\tR\n" +
+	"tournament\x12\x14\n" +
+	"\x05team1\x18\x04 \x01(\tR\x05team1\x12\x14\n" +
+	"\x05team2\x18\x05 \x01(\tR\x05team2\x12\x16\n" +
+	"\x06score1\x18\x06 \x01(\tR\x06score1\x12\x16\n" +
+	"\x06score2\x18\a \x01(\tR\x06score2\x12\x12\n" +
+	"\x04date\x18\b \x01(\tR\x04date\x12\x1d\n" +
+	"\n" +
+	"match_time\x18\t \x01(\tR\tmatchTime\x12(\n" +
+	"\x10time_until_match\x18\n" +
+	" \x01(\tR\x0etimeUntilMatch\x12=\n" +
+	"\fscheduled_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\vscheduledAt\"\x7f\n" +
+	"\aRanking\x12\x12\n" +
+	"\x04rank\x18\x01 \x01(\x05R\x04rank\x12\x16\n" +
+	"\x06region\x18\x02 \x01(\tR\x06region\x12\x1b\n" +
+	"\tteam_name\x18\x03 \x01(\tR\bteamName\x12\x10\n" +
+	"\x03elo\x18\x04 \x01(\x05R\x03elo\x12\x19\n" +
+	"\bteam_url\x18\x05 \x01(\tR\ateamUrl\"\xb0\x01\n" +
+	"\vMatchDetail\x12)\n" +
+	"\x05match\x18\x01 \x01(\v2\x13.vlrscrape.v1.MatchR\x05match\x12:\n" +
+	"\rteam1_ranking\x18\x02 \x01(\v2\x15.vlrscrape.v1.RankingR\fteam1Ranking\x12:\n" +
+	"\rteam2_ranking\x18\x03 \x01(\v2\x15.vlrscrape.v1.RankingR\fteam2Ranking\"\xab\x01\n" +
+	"\x04Team\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x19\n" +
+	"\bteam_url\x18\x03 \x01(\tR\ateamUrl\x121\n" +
+	"\brankings\x18\x04 \x03(\v2\x15.vlrscrape.v1.RankingR\brankings\x12-\n" +
+	"\amatches\x18\x05 \x03(\v2\x13.vlrscrape.v1.MatchR\amatches\"\"\n" +
+	"\x10GetThreadRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"!\n" +
+	"\x0fGetMatchRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"$\n" +
+	"\x0eGetTeamRequest\x12\x12\n" +
+	"\x04slug\x18\x01 \x01(\tR\x04slug\"\xd4\x01\n" +
+	"\vListRequest\x12@\n" +
+	"\afilters\x18\x01 \x03(\v2&.vlrscrape.v1.ListRequest.FiltersEntryR\afilters\x12\x12\n" +
+	"\x04sort\x18\x02 \x01(\tR\x04sort\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x1a:\n" +
+	"\fFiltersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"^\n" +
+	"\x13ListRankingsRequest\x12\x16\n" +
+	"\x06region\x18\x01 \x01(\tR\x06region\x12/\n" +
+	"\x05query\x18\x02 \x01(\v2\x19.vlrscrape.v1.ListRequestR\x05query\"\x83\x01\n" +
+	"\x13ListThreadsResponse\x12.\n" +
+	"\athreads\x18\x01 \x03(\v2\x14.vlrscrape.v1.ThreadR\athreads\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken\"\x82\x01\n" +
+	"\x13ListMatchesResponse\x12-\n" +
+	"\amatches\x18\x01 \x03(\v2\x13.vlrscrape.v1.MatchR\amatches\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken\"\x87\x01\n" +
+	"\x14ListRankingsResponse\x121\n" +
+	"\brankings\x18\x01 \x03(\v2\x15.vlrscrape.v1.RankingR\brankings\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken\"9\n" +
+	"\x13WatchMatchesRequest\x12\"\n" +
+	"\rlast_event_id\x18\x01 \x01(\x03R\vlastEventId\"\xbc\x02\n" +
+	"\n" +
+	"MatchEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x121\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x1d.vlrscrape.v1.MatchEvent.TypeR\x04type\x12.\n" +
+	"\x04time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12)\n" +
+	"\x05match\x18\x04 \x01(\v2\x13.vlrscrape.v1.MatchR\x05match\x12-\n" +
+	"\amatches\x18\x05 \x03(\v2\x13.vlrscrape.v1.MatchR\amatches\"a\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rTYPE_SNAPSHOT\x10\x01\x12\r\n" +
+	"\tTYPE_LIVE\x10\x02\x12\x0e\n" +
+	"\n" +
+	"TYPE_SCORE\x10\x03\x12\x11\n" +
+	"\rTYPE_FINISHED\x10\x042\x91\x04\n" +
+	"\tVLRScrape\x12A\n" +
+	"\tGetThread\x12\x1e.vlrscrape.v1.GetThreadRequest\x1a\x14.vlrscrape.v1.Thread\x12D\n" +
+	"\bGetMatch\x12\x1d.vlrscrape.v1.GetMatchRequest\x1a\x19.vlrscrape.v1.MatchDetail\x12;\n" +
+	"\aGetTeam\x12\x1c.vlrscrape.v1.GetTeamRequest\x1a\x12.vlrscrape.v1.Team\x12K\n" +
+	"\vListThreads\x12\x19.vlrscrape.v1.ListRequest\x1a!.vlrscrape.v1.ListThreadsResponse\x12K\n" +
+	"\vListMatches\x12\x19.vlrscrape.v1.ListRequest\x1a!.vlrscrape.v1.ListMatchesResponse\x12U\n" +
+	"\fListRankings\x12!.vlrscrape.v1.ListRankingsRequest\x1a\".vlrscrape.v1.ListRankingsResponse\x12M\n" +
+	"\fWatchMatches\x12!.vlrscrape.v1.WatchMatchesRequest\x1a\x18.vlrscrape.v1.MatchEvent0\x01B/Z-github.com/mrovengerdev/vlrscrape/vlrscrapepbb\x06proto3"
+
+var (
+	file_vlrscrape_proto_rawDescOnce sync.Once
+	file_vlrscrape_proto_rawDescData []byte
+)
+
+func file_vlrscrape_proto_rawDescGZIP() []byte {
+	file_vlrscrape_proto_rawDescOnce.Do(func() {
+		file_vlrscrape_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_vlrscrape_proto_rawDesc), len(file_vlrscrape_proto_rawDesc)))
+	})
+	return file_vlrscrape_proto_rawDescData
+}
+
+var file_vlrscrape_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_vlrscrape_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_vlrscrape_proto_goTypes = []any{
+	(MatchEvent_Type)(0),          // 0: vlrscrape.v1.MatchEvent.Type
+	(*Thread)(nil),                // 1: vlrscrape.v1.Thread
+	(*Match)(nil),                 // 2: vlrscrape.v1.Match
+	(*Ranking)(nil),               // 3: vlrscrape.v1.Ranking
+	(*MatchDetail)(nil),           // 4: vlrscrape.v1.MatchDetail
+	(*Team)(nil),                  // 5: vlrscrape.v1.Team
+	(*GetThreadRequest)(nil),      // 6: vlrscrape.v1.GetThreadRequest
+	(*GetMatchRequest)(nil),       // 7: vlrscrape.v1.GetMatchRequest
+	(*GetTeamRequest)(nil),        // 8: vlrscrape.v1.GetTeamRequest
+	(*ListRequest)(nil),           // 9: vlrscrape.v1.ListRequest
+	(*ListRankingsRequest)(nil),   // 10: vlrscrape.v1.ListRankingsRequest
+	(*ListThreadsResponse)(nil),   // 11: vlrscrape.v1.ListThreadsResponse
+	(*ListMatchesResponse)(nil),   // 12: vlrscrape.v1.ListMatchesResponse
+	(*ListRankingsResponse)(nil),  // 13: vlrscrape.v1.ListRankingsResponse
+	(*WatchMatchesRequest)(nil),   // 14: vlrscrape.v1.WatchMatchesRequest
+	(*MatchEvent)(nil),            // 15: vlrscrape.v1.MatchEvent
+	nil,                           // 16: vlrscrape.v1.ListRequest.FiltersEntry
+	(*timestamppb.Timestamp)(nil), // 17: google.protobuf.Timestamp
+}
+var file_vlrscrape_proto_depIdxs = []int32{
+	17, // 0: vlrscrape.v1.Thread.published_at:type_name -> google.protobuf.Timestamp
+	17, // 1: vlrscrape.v1.Match.scheduled_at:type_name -> google.protobuf.Timestamp
+	2,  // 2: vlrscrape.v1.MatchDetail.match:type_name -> vlrscrape.v1.Match
+	3,  // 3: vlrscrape.v1.MatchDetail.team1_ranking:type_name -> vlrscrape.v1.Ranking
+	3,  // 4: vlrscrape.v1.MatchDetail.team2_ranking:type_name -> vlrscrape.v1.Ranking
+	3,  // 5: vlrscrape.v1.Team.rankings:type_name -> vlrscrape.v1.Ranking
+	2,  // 6: vlrscrape.v1.Team.matches:type_name -> vlrscrape.v1.Match
+	16, // 7: vlrscrape.v1.ListRequest.filters:type_name -> vlrscrape.v1.ListRequest.FiltersEntry
+	9,  // 8: vlrscrape.v1.ListRankingsRequest.query:type_name -> vlrscrape.v1.ListRequest
+	1,  // 9: vlrscrape.v1.ListThreadsResponse.threads:type_name -> vlrscrape.v1.Thread
+	2,  // 10: vlrscrape.v1.ListMatchesResponse.matches:type_name -> vlrscrape.v1.Match
+	3,  // 11: vlrscrape.v1.ListRankingsResponse.rankings:type_name -> vlrscrape.v1.Ranking
+	0,  // 12: vlrscrape.v1.MatchEvent.type:type_name -> vlrscrape.v1.MatchEvent.Type
+	17, // 13: vlrscrape.v1.MatchEvent.time:type_name -> google.protobuf.Timestamp
+	2,  // 14: vlrscrape.v1.MatchEvent.match:type_name -> vlrscrape.v1.Match
+	2,  // 15: vlrscrape.v1.MatchEvent.matches:type_name -> vlrscrape.v1.Match
+	6,  // 16: vlrscrape.v1.VLRScrape.GetThread:input_type -> vlrscrape.v1.GetThreadRequest
+	7,  // 17: vlrscrape.v1.VLRScrape.GetMatch:input_type -> vlrscrape.v1.GetMatchRequest
+	8,  // 18: vlrscrape.v1.VLRScrape.GetTeam:input_type -> vlrscrape.v1.GetTeamRequest
+	9,  // 19: vlrscrape.v1.VLRScrape.ListThreads:input_type -> vlrscrape.v1.ListRequest
+	9,  // 20: vlrscrape.v1.VLRScrape.ListMatches:input_type -> vlrscrape.v1.ListRequest
+	10, // 21: vlrscrape.v1.VLRScrape.ListRankings:input_type -> vlrscrape.v1.ListRankingsRequest
+	14, // 22: vlrscrape.v1.VLRScrape.WatchMatches:input_type -> vlrscrape.v1.WatchMatchesRequest
+	1,  // 23: vlrscrape.v1.VLRScrape.GetThread:output_type -> vlrscrape.v1.Thread
+	4,  // 24: vlrscrape.v1.VLRScrape.GetMatch:output_type -> vlrscrape.v1.MatchDetail
+	5,  // 25: vlrscrape.v1.VLRScrape.GetTeam:output_type -> vlrscrape.v1.Team
+	11, // 26: vlrscrape.v1.VLRScrape.ListThreads:output_type -> vlrscrape.v1.ListThreadsResponse
+	12, // 27: vlrscrape.v1.VLRScrape.ListMatches:output_type -> vlrscrape.v1.ListMatchesResponse
+	13, // 28: vlrscrape.v1.VLRScrape.ListRankings:output_type -> vlrscrape.v1.ListRankingsResponse
+	15, // 29: vlrscrape.v1.VLRScrape.WatchMatches:output_type -> vlrscrape.v1.MatchEvent
+	23, // [23:30] is the sub-list for method output_type
+	16, // [16:23] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_vlrscrape_proto_init() }
+func file_vlrscrape_proto_init() {
+	if File_vlrscrape_proto != nil {
+		return
+	}
+	file_vlrscrape_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_vlrscrape_proto_rawDesc), len(file_vlrscrape_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_vlrscrape_proto_goTypes,
+		DependencyIndexes: file_vlrscrape_proto_depIdxs,
+		EnumInfos:         file_vlrscrape_proto_enumTypes,
+		MessageInfos:      file_vlrscrape_proto_msgTypes,
+	}.Build()
+	File_vlrscrape_proto = out.File
+	file_vlrscrape_proto_goTypes = nil
+	file_vlrscrape_proto_depIdxs = nil
+}
@@ -0,0 +1,373 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: vlrscrape.proto
+
+package vlrscrapepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	VLRScrape_GetThread_FullMethodName    = "/vlrscrape.v1.VLRScrape/GetThread"
+	VLRScrape_GetMatch_FullMethodName     = "/vlrscrape.v1.VLRScrape/GetMatch"
+	VLRScrape_GetTeam_FullMethodName      = "/vlrscrape.v1.VLRScrape/GetTeam"
+	VLRScrape_ListThreads_FullMethodName  = "/vlrscrape.v1.VLRScrape/ListThreads"
+	VLRScrape_ListMatches_FullMethodName  = "/vlrscrape.v1.VLRScrape/ListMatches"
+	VLRScrape_ListRankings_FullMethodName = "/vlrscrape.v1.VLRScrape/ListRankings"
+	VLRScrape_WatchMatches_FullMethodName = "/vlrscrape.v1.VLRScrape/WatchMatches"
+)
+
+// VLRScrapeClient is the client API for VLRScrape service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Typed access to the scraped threads, matches and rankings, served alongside the REST API.
+type VLRScrapeClient interface {
+	// A single thread by ID, searched for in every snapshot.
+	GetThread(ctx context.Context, in *GetThreadRequest, opts ...grpc.CallOption) (*Thread, error)
+	// A single match by ID, searched for in every snapshot, with the current ranking of each team.
+	GetMatch(ctx context.Context, in *GetMatchRequest, opts ...grpc.CallOption) (*MatchDetail, error)
+	// A team with its regional rankings and its matches in the newest snapshot.
+	GetTeam(ctx context.Context, in *GetTeamRequest, opts ...grpc.CallOption) (*Team, error)
+	// Threads of the newest snapshot.
+	ListThreads(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListThreadsResponse, error)
+	// Matches of the newest snapshot.
+	ListMatches(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListMatchesResponse, error)
+	// Rankings of a region.
+	ListRankings(ctx context.Context, in *ListRankingsRequest, opts ...grpc.CallOption) (*ListRankingsResponse, error)
+	// Matches going live, score changes and finished matches, starting with every match live at the time
+	// unless the stream resumes from last_event_id.
+	WatchMatches(ctx context.Context, in *WatchMatchesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MatchEvent], error)
+}
+
+type vLRScrapeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVLRScrapeClient(cc grpc.ClientConnInterface) VLRScrapeClient {
+	return &vLRScrapeClient{cc}
+}
+
+func (c *vLRScrapeClient) GetThread(ctx context.Context, in *GetThreadRequest, opts ...grpc.CallOption) (*Thread, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Thread)
+	err := c.cc.Invoke(ctx, VLRScrape_GetThread_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vLRScrapeClient) GetMatch(ctx context.Context, in *GetMatchRequest, opts ...grpc.CallOption) (*MatchDetail, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MatchDetail)
+	err := c.cc.Invoke(ctx, VLRScrape_GetMatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vLRScrapeClient) GetTeam(ctx context.Context, in *GetTeamRequest, opts ...grpc.CallOption) (*Team, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Team)
+	err := c.cc.Invoke(ctx, VLRScrape_GetTeam_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vLRScrapeClient) ListThreads(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListThreadsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListThreadsResponse)
+	err := c.cc.Invoke(ctx, VLRScrape_ListThreads_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vLRScrapeClient) ListMatches(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListMatchesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMatchesResponse)
+	err := c.cc.Invoke(ctx, VLRScrape_ListMatches_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vLRScrapeClient) ListRankings(ctx context.Context, in *ListRankingsRequest, opts ...grpc.CallOption) (*ListRankingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRankingsResponse)
+	err := c.cc.Invoke(ctx, VLRScrape_ListRankings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vLRScrapeClient) WatchMatches(ctx context.Context, in *WatchMatchesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MatchEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &VLRScrape_ServiceDesc.Streams[0], VLRScrape_WatchMatches_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchMatchesRequest, MatchEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VLRScrape_WatchMatchesClient = grpc.ServerStreamingClient[MatchEvent]
+
+// VLRScrapeServer is the server API for VLRScrape service.
+// All implementations must embed UnimplementedVLRScrapeServer
+// for forward compatibility.
+//
+// Typed access to the scraped threads, matches and rankings, served alongside the REST API.
+type VLRScrapeServer interface {
+	// A single thread by ID, searched for in every snapshot.
+	GetThread(context.Context, *GetThreadRequest) (*Thread, error)
+	// A single match by ID, searched for in every snapshot, with the current ranking of each team.
+	GetMatch(context.Context, *GetMatchRequest) (*MatchDetail, error)
+	// A team with its regional rankings and its matches in the newest snapshot.
+	GetTeam(context.Context, *GetTeamRequest) (*Team, error)
+	// Threads of the newest snapshot.
+	ListThreads(context.Context, *ListRequest) (*ListThreadsResponse, error)
+	// Matches of the newest snapshot.
+	ListMatches(context.Context, *ListRequest) (*ListMatchesResponse, error)
+	// Rankings of a region.
+	ListRankings(context.Context, *ListRankingsRequest) (*ListRankingsResponse, error)
+	// Matches going live, score changes and finished matches, starting with every match live at the time
+	// unless the stream resumes from last_event_id.
+	WatchMatches(*WatchMatchesRequest, grpc.ServerStreamingServer[MatchEvent]) error
+	mustEmbedUnimplementedVLRScrapeServer()
+}
+
+// UnimplementedVLRScrapeServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedVLRScrapeServer struct{}
+
+func (UnimplementedVLRScrapeServer) GetThread(context.Context, *GetThreadRequest) (*Thread, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetThread not implemented")
+}
+func (UnimplementedVLRScrapeServer) GetMatch(context.Context, *GetMatchRequest) (*MatchDetail, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetMatch not implemented")
+}
+func (UnimplementedVLRScrapeServer) GetTeam(context.Context, *GetTeamRequest) (*Team, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTeam not implemented")
+}
+func (UnimplementedVLRScrapeServer) ListThreads(context.Context, *ListRequest) (*ListThreadsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListThreads not implemented")
+}
+func (UnimplementedVLRScrapeServer) ListMatches(context.Context, *ListRequest) (*ListMatchesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListMatches not implemented")
+}
+func (UnimplementedVLRScrapeServer) ListRankings(context.Context, *ListRankingsRequest) (*ListRankingsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRankings not implemented")
+}
+func (UnimplementedVLRScrapeServer) WatchMatches(*WatchMatchesRequest, grpc.ServerStreamingServer[MatchEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchMatches not implemented")
+}
+func (UnimplementedVLRScrapeServer) mustEmbedUnimplementedVLRScrapeServer() {}
+func (UnimplementedVLRScrapeServer) testEmbeddedByValue()                   {}
+
+// UnsafeVLRScrapeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VLRScrapeServer will
+// result in compilation errors.
+type UnsafeVLRScrapeServer interface {
+	mustEmbedUnimplementedVLRScrapeServer()
+}
+
+func RegisterVLRScrapeServer(s grpc.ServiceRegistrar, srv VLRScrapeServer) {
+	// If the following call panics, it indicates UnimplementedVLRScrapeServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&VLRScrape_ServiceDesc, srv)
+}
+
+func _VLRScrape_GetThread_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetThreadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VLRScrapeServer).GetThread(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VLRScrape_GetThread_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VLRScrapeServer).GetThread(ctx, req.(*GetThreadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VLRScrape_GetMatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VLRScrapeServer).GetMatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VLRScrape_GetMatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VLRScrapeServer).GetMatch(ctx, req.(*GetMatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VLRScrape_GetTeam_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTeamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VLRScrapeServer).GetTeam(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VLRScrape_GetTeam_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VLRScrapeServer).GetTeam(ctx, req.(*GetTeamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VLRScrape_ListThreads_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VLRScrapeServer).ListThreads(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VLRScrape_ListThreads_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VLRScrapeServer).ListThreads(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VLRScrape_ListMatches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VLRScrapeServer).ListMatches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VLRScrape_ListMatches_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VLRScrapeServer).ListMatches(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VLRScrape_ListRankings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRankingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VLRScrapeServer).ListRankings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VLRScrape_ListRankings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VLRScrapeServer).ListRankings(ctx, req.(*ListRankingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VLRScrape_WatchMatches_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchMatchesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VLRScrapeServer).WatchMatches(m, &grpc.GenericServerStream[WatchMatchesRequest, MatchEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VLRScrape_WatchMatchesServer = grpc.ServerStreamingServer[MatchEvent]
+
+// VLRScrape_ServiceDesc is the grpc.ServiceDesc for VLRScrape service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VLRScrape_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "vlrscrape.v1.VLRScrape",
+	HandlerType: (*VLRScrapeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetThread",
+			Handler:    _VLRScrape_GetThread_Handler,
+		},
+		{
+			MethodName: "GetMatch",
+			Handler:    _VLRScrape_GetMatch_Handler,
+		},
+		{
+			MethodName: "GetTeam",
+			Handler:    _VLRScrape_GetTeam_Handler,
+		},
+		{
+			MethodName: "ListThreads",
+			Handler:    _VLRScrape_ListThreads_Handler,
+		},
+		{
+			MethodName: "ListMatches",
+			Handler:    _VLRScrape_ListMatches_Handler,
+		},
+		{
+			MethodName: "ListRankings",
+			Handler:    _VLRScrape_ListRankings_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchMatches",
+			Handler:       _VLRScrape_WatchMatches_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "vlrscrape.proto",
+}